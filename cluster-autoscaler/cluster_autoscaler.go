@@ -97,6 +97,7 @@ var (
 	cloudProviderFlag          = flag.String("cloud-provider", "gce", "Cloud provider type. Allowed values: gce, aws")
 	maxEmptyBulkDeleteFlag     = flag.Int("max-empty-bulk-delete", 10, "Maximum number of empty nodes that can be deleted at the same time.")
 	maxGratefulTerminationFlag = flag.Int("max-grateful-termination-sec", 60, "Maximum number of seconds CA waints for pod termination when trying to scale down a node.")
+	cordonTimeoutFlag          = flag.Duration("cordon-timeout", 10*time.Second, "Maximum time CA waits for a node to be marked as unschedulable when trying to scale it down.")
 
 	// AvailableEstimators is a list of available estimators.
 	AvailableEstimators = []string{BasicEstimatorName, BinpackingEstimatorName}
@@ -222,6 +223,7 @@ func run(_ <-chan struct{}) {
 		EstimatorName:                 *estimatorFlag,
 		ExpanderStrategy:              expanderStrategy,
 		MaxGratefulTerminationSec:     *maxGratefulTerminationFlag,
+		CordonTimeout:                 *cordonTimeoutFlag,
 	}
 
 	for {
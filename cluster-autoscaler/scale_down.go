@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	gocontext "context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -25,6 +26,7 @@ import (
 
 	"k8s.io/contrib/cluster-autoscaler/cloudprovider"
 	"k8s.io/contrib/cluster-autoscaler/simulator"
+	"k8s.io/contrib/cluster-autoscaler/utils/drain"
 	"k8s.io/kubernetes/pkg/api/errors"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
 	kube_client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
@@ -53,6 +55,35 @@ const (
 	ToBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
 )
 
+const (
+	// defaultCordonTimeout is used when no positive cordon timeout is configured.
+	defaultCordonTimeout = 10 * time.Second
+	// maxDrainApprovalWait is how long scale down waits for a drain to be approved.
+	maxDrainApprovalWait = 5 * time.Minute
+	// maxAPIServerWait is how long a drain waits for the API server to become ready.
+	maxAPIServerWait = 5 * time.Minute
+	// apiServerCheckInterval is the initial interval between API server readiness checks.
+	apiServerCheckInterval = time.Second
+)
+
+// ErrCordonTimeout describes the failure reported by CordonTimeoutError.
+var ErrCordonTimeout = fmt.Errorf("timed out while marking the node as unschedulable")
+
+// CordonTimeoutError is returned when marking a node as unschedulable doesn't complete in time.
+type CordonTimeoutError struct {
+	NodeName string
+}
+
+func (e *CordonTimeoutError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrCordonTimeout, e.NodeName)
+}
+
+// IsCordonTimeout checks whether err is a CordonTimeoutError.
+func IsCordonTimeout(err error) bool {
+	_, ok := err.(*CordonTimeoutError)
+	return ok
+}
+
 // FindUnneededNodes calculates which nodes are not needed, i.e. all pods can be scheduled somewhere else,
 // and updates unneededNodes map accordingly. It also returns information where pods can be rescheduld and
 // node utilization level.
@@ -138,24 +169,15 @@ func ScaleDown(
 				continue
 			}
 
-			nodeGroup, err := context.CloudProvider.NodeGroupForNode(node)
-			if err != nil {
-				glog.Errorf("Error while checking node group for %s: %v", node.Name, err)
-				continue
-			}
-			if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
-				glog.V(4).Infof("Skipping %s - no node group config", node.Name)
-				continue
-			}
-
-			size, err := nodeGroup.TargetSize()
-			if err != nil {
-				glog.Errorf("Error while checking node group size %s: %v", nodeGroup.Id(), err)
-				continue
-			}
-
-			if size <= nodeGroup.MinSize() {
-				glog.V(1).Infof("Skipping %s - node group min size reached", node.Name)
+			if _, _, err := getNodeDrainConstraint(node, context.CloudProvider); err != nil {
+				switch err {
+				case errNoNodeGroupConfig:
+					glog.V(4).Infof("Skipping %s - %v", node.Name, err)
+				case errNodeGroupMinSizeReached:
+					glog.V(1).Infof("Skipping %s - %v", node.Name, err)
+				default:
+					glog.Errorf("Error while checking node group for %s: %v", node.Name, err)
+				}
 				continue
 			}
 
@@ -224,6 +246,32 @@ func ScaleDown(
 	return ScaleDownNodeDeleted, nil
 }
 
+var (
+	errNoNodeGroupConfig       = fmt.Errorf("no node group config")
+	errNodeGroupMinSizeReached = fmt.Errorf("node group min size reached")
+)
+
+// Returns the min and current size of the node group of the node. errNoNodeGroupConfig is returned if
+// the node doesn't belong to any node group and errNodeGroupMinSizeReached if draining it would take
+// the group below its min size. Other errors come from the cloud provider.
+func getNodeDrainConstraint(node *apiv1.Node, cloudProvider cloudprovider.CloudProvider) (minPoolSize, currentPoolSize int, err error) {
+	nodeGroup, err := cloudProvider.NodeGroupForNode(node)
+	if err != nil {
+		return 0, 0, err
+	}
+	if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+		return 0, 0, errNoNodeGroupConfig
+	}
+	size, err := nodeGroup.TargetSize()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get size of node group %s: %v", nodeGroup.Id(), err)
+	}
+	if size <= nodeGroup.MinSize() {
+		return nodeGroup.MinSize(), size, errNodeGroupMinSizeReached
+	}
+	return nodeGroup.MinSize(), size, nil
+}
+
 // This functions finds empty nodes among passed candidates and returns a list of empty nodes
 // that can be deleted at the same time.
 func getEmptyNodes(candidates []*apiv1.Node, pods []*apiv1.Pod, maxEmptyBulkDelete int, cloudProvider cloudprovider.CloudProvider) []*apiv1.Node {
@@ -267,34 +315,87 @@ func getEmptyNodes(candidates []*apiv1.Node, pods []*apiv1.Pod, maxEmptyBulkDele
 }
 
 func deleteNode(context AutoscalingContext, node *apiv1.Node, pods []*apiv1.Pod) error {
-	if err := drainNode(node, pods, context.ClientSet, context.Recorder, context.MaxGratefulTerminationSec); err != nil {
+	revert := func() {
+		if err := revertPartialDrain(node, context.ClientSet, context.Recorder); err != nil {
+			glog.Errorf("Failed to revert drain of %s: %v", node.Name, err)
+		}
+	}
+	if context.ApprovalRequired {
+		approvalCtx, cancel := gocontext.WithTimeout(gocontext.Background(), maxDrainApprovalWait)
+		changeID, err := drain.RequestDrainApproval(approvalCtx, context.ChangeAdvisoryBoard, node, pods, "scale down")
+		cancel()
+		if err != nil {
+			return err
+		}
+		glog.V(1).Infof("Drain of %s approved in change %s", node.Name, changeID)
+	}
+	if err := drainNode(node, pods, context.ClientSet, context.Recorder, context.MaxGratefulTerminationSec,
+		context.CordonTimeout); err != nil {
+		revert()
+		return err
+	}
+	if err := deleteNodeFromCloudProvider(node, context.CloudProvider, context.Recorder); err != nil {
+		revert()
 		return err
 	}
-	return deleteNodeFromCloudProvider(node, context.CloudProvider, context.Recorder)
+	return nil
 }
 
 // Performs drain logic on the node. Marks the node as unschedulable and later removes all pods, giving
-// them up to MaxGracefulTerminationTime to finish.
+// them up to MaxGracefulTerminationTime to finish. Pending pods are removed immediately. Marking the
+// node may take up to cordonTimeout (defaultCordonTimeout if not positive), otherwise a
+// CordonTimeoutError is returned. The drain doesn't start until the API server is ready, which may take
+// up to maxAPIServerWait. Nodes with only DaemonSet and mirror pods left are not marked at all.
 func drainNode(node *apiv1.Node, pods []*apiv1.Pod, client kube_client.Interface, recorder kube_record.EventRecorder,
-	maxGratefulTerminationSec int) error {
-	if err := markToBeDeleted(node, client, recorder); err != nil {
+	maxGratefulTerminationSec int, cordonTimeout time.Duration) error {
+	apiServerCtx, cancel := gocontext.WithTimeout(gocontext.Background(), maxAPIServerWait)
+	err := drain.WaitForAPIServerReady(apiServerCtx, client, apiServerCheckInterval)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to drain node %s: %v", node.Name, err)
+	}
+	if cordonTimeout <= 0 {
+		cordonTimeout = defaultCordonTimeout
+	}
+	drained, err := drain.IsNodeAlreadyDrained(gocontext.Background(), client, node.Name)
+	if err != nil {
+		glog.Warningf("Failed to check whether %s is already drained: %v", node.Name, err)
+	}
+	if drained {
+		glog.V(1).Infof("Only DaemonSet and mirror pods left on %s, skipping drain", node.Name)
+		return nil
+	}
+	if err := markToBeDeletedWithTimeout(node, client, recorder, cordonTimeout); err != nil {
 		return err
 	}
 
-	maxGraceful64 := int64(maxGratefulTerminationSec)
-	for _, pod := range pods {
-		recorder.Eventf(pod, apiv1.EventTypeNormal, "ScaleDown", "deleting pod for node scale down")
-		err := client.Core().Pods(pod.Namespace).Delete(pod.Name, &apiv1.DeleteOptions{
-			GracePeriodSeconds: &maxGraceful64,
-		})
-		if err != nil {
-			glog.Errorf("Failed to delete %s/%s: %v", pod.Namespace, pod.Name, err)
+	// Pods already being deleted, e.g. by another autoscaler instance, are only waited for.
+	podsToDelete := pods
+	if terminating, err := drain.GetPodsBeingEvicted(pods, client); err != nil {
+		glog.Warningf("Failed to check for terminating pods on %s: %v", node.Name, err)
+	} else if len(terminating) > 0 {
+		podsToDelete = excludePods(pods, terminating)
+	}
+
+	// Pending pods have no running state to lose, so they are deleted without a grace period.
+	pendingPods, runningPods := drain.FilterPendingPods(podsToDelete)
+	noGracePeriod := int64(0)
+	for _, pod := range pendingPods {
+		deletePodForScaleDown(pod, noGracePeriod, client, recorder)
+	}
+	maxGracePeriod := int64(maxGratefulTerminationSec)
+	for _, pod := range runningPods {
+		gracePeriod := podGracePeriod(pod, maxGratefulTerminationSec)
+		if gracePeriod > maxGracePeriod {
+			maxGracePeriod = gracePeriod
 		}
+		deletePodForScaleDown(pod, gracePeriod, client, recorder)
 	}
 	allGone := true
 
-	// Wait up to MaxGracefulTerminationTime.
-	for start := time.Now(); time.Now().Sub(start) < time.Duration(maxGratefulTerminationSec)*time.Second; time.Sleep(5 * time.Second) {
+	// Wait up to the longest grace period given to the pods.
+	maxWait := time.Duration(maxGracePeriod) * time.Second
+	for start := time.Now(); time.Now().Sub(start) < maxWait; time.Sleep(5 * time.Second) {
 		allGone = true
 		for _, pod := range pods {
 			podreturned, err := client.Core().Pods(pod.Namespace).Get(pod.Name)
@@ -319,6 +420,90 @@ func drainNode(node *apiv1.Node, pods []*apiv1.Pod, client kube_client.Interface
 	return nil
 }
 
+// Returns pods without the ones in excluded.
+func excludePods(pods []*apiv1.Pod, excluded []*apiv1.Pod) []*apiv1.Pod {
+	skip := make(map[*apiv1.Pod]bool, len(excluded))
+	for _, pod := range excluded {
+		skip[pod] = true
+	}
+	result := make([]*apiv1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if !skip[pod] {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func deletePodForScaleDown(pod *apiv1.Pod, gracePeriod int64, client kube_client.Interface,
+	recorder kube_record.EventRecorder) {
+	recorder.Eventf(pod, apiv1.EventTypeNormal, "ScaleDown", "deleting pod for node scale down")
+	err := client.Core().Pods(pod.Namespace).Delete(pod.Name, &apiv1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+	})
+	if err != nil {
+		glog.Errorf("Failed to delete %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
+
+// Returns the grace period, in seconds, the pod is deleted with. Kubelet runs PreStop hooks within the
+// grace period, so pods with hooks get the time the hooks may take on top of maxGratefulTerminationSec
+// for shutting down after SIGTERM. Mesh-managed pods get their PreStop grace for draining connections
+// as well. The extra time is capped at maxGratefulTerminationSec.
+func podGracePeriod(pod *apiv1.Pod, maxGratefulTerminationSec int) int64 {
+	limit := time.Duration(maxGratefulTerminationSec) * time.Second
+	extra := drain.PreStopTimeout(pod)
+	meshGrace, err := drain.GetPreStopGrace(pod, 0)
+	if err != nil {
+		glog.Warningf("Ignoring PreStop grace of %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	extra += meshGrace
+	if extra > limit {
+		extra = limit
+	}
+	return int64(maxGratefulTerminationSec) + int64(extra/time.Second)
+}
+
+// Runs markToBeDeleted, giving up after the given timeout. The client doesn't support cancellation
+// so the update may still complete after a CordonTimeoutError is returned. In that case the taint is
+// released again in the background, as no drain follows.
+func markToBeDeletedWithTimeout(node *apiv1.Node, client kube_client.Interface, recorder kube_record.EventRecorder,
+	timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- markToBeDeleted(node, client, recorder)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		glog.Warningf("Marking node %v as unschedulable didn't finish within %v", node.Name, timeout)
+		go func() {
+			if err := <-result; err != nil {
+				return
+			}
+			freshNode, err := client.Core().Nodes().Get(node.Name)
+			if err != nil || freshNode == nil {
+				glog.Errorf("Failed to get node %v to release its taint after cordon timeout: %v", node.Name, err)
+				return
+			}
+			cleanToBeDeleted([]*apiv1.Node{freshNode}, client, recorder)
+		}()
+		return &CordonTimeoutError{NodeName: node.Name}
+	}
+}
+
+// Reverts a drain that didn't end with the node removal by releasing the ToBeDeleted taint. Deleted pods
+// are not recreated: scale down only drains nodes whose pods are all managed by controllers, which
+// replace them on their own.
+func revertPartialDrain(node *apiv1.Node, client kube_client.Interface, recorder kube_record.EventRecorder) error {
+	freshNode, err := client.Core().Nodes().Get(node.Name)
+	if err != nil || freshNode == nil {
+		return fmt.Errorf("failed to get node %v: %v", node.Name, err)
+	}
+	return cleanToBeDeleted([]*apiv1.Node{freshNode}, client, recorder)
+}
+
 // Sets unschedulable=true and adds an annotation.
 func markToBeDeleted(node *apiv1.Node, client kube_client.Interface, recorder kube_record.EventRecorder) error {
 	// Get the newest version of the node.
@@ -17,14 +17,18 @@ limitations under the License.
 package main
 
 import (
+	"sync"
 	"testing"
 	"time"
 
+	"k8s.io/contrib/cluster-autoscaler/cloudprovider"
 	"k8s.io/contrib/cluster-autoscaler/simulator"
+	"k8s.io/contrib/cluster-autoscaler/utils/drain"
 	. "k8s.io/contrib/cluster-autoscaler/utils/test"
 
 	"k8s.io/kubernetes/pkg/api/errors"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
 	"k8s.io/kubernetes/pkg/client/testing/core"
 	"k8s.io/kubernetes/pkg/runtime"
@@ -115,13 +119,159 @@ func TestDrainNode(t *testing.T) {
 		updatedNodes <- obj.Name
 		return true, obj, nil
 	})
-	err := drainNode(n1, []*apiv1.Pod{p1, p2}, fakeClient, createEventRecorder(fakeClient), 20)
+	err := drainNode(n1, []*apiv1.Pod{p1, p2}, fakeClient, createEventRecorder(fakeClient), 20, 10*time.Second)
 	assert.NoError(t, err)
 	assert.Equal(t, p1.Name, getStringFromChan(deletedPods))
 	assert.Equal(t, p2.Name, getStringFromChan(deletedPods))
 	assert.Equal(t, n1.Name, getStringFromChan(updatedNodes))
 }
 
+func TestDrainNodeSkipsTerminatingPods(t *testing.T) {
+	deletedPods := make(chan string, 10)
+	fakeClient := &fake.Clientset{}
+
+	p1 := BuildTestPod("p1", 100, 0)
+	p2 := BuildTestPod("p2", 300, 0)
+	n1 := BuildTestNode("n1", 1000, 1000)
+
+	fakeClient.Fake.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.PodList{Items: []apiv1.Pod{*p1, *p2}}, nil
+	})
+	checks := 0
+	fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if action.(core.GetAction).GetName() == p2.Name && checks == 0 {
+			checks++
+			now := metav1.Now()
+			terminating := *p2
+			terminating.DeletionTimestamp = &now
+			return true, &terminating, nil
+		}
+		return true, nil, errors.NewNotFound(apiv1.Resource("pod"), "whatever")
+	})
+	fakeClient.Fake.AddReactor("get", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		return true, n1, nil
+	})
+	fakeClient.Fake.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		deletedPods <- action.(core.DeleteAction).GetName()
+		return true, nil, nil
+	})
+	fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		return true, action.(core.UpdateAction).GetObject(), nil
+	})
+	err := drainNode(n1, []*apiv1.Pod{p1, p2}, fakeClient, createEventRecorder(fakeClient), 20, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, p1.Name, getStringFromChan(deletedPods))
+	assert.Equal(t, 0, len(deletedPods))
+}
+
+func TestDrainNodeCordonTimeout(t *testing.T) {
+	fakeClient := &fake.Clientset{}
+	n1 := BuildTestNode("n1", 1000, 1000)
+	release := make(chan struct{})
+	updatedNodes := make(chan *apiv1.Node, 10)
+
+	var lock sync.Mutex
+	stored := n1
+	fakeClient.Fake.AddReactor("get", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		node := *stored
+		node.Annotations = map[string]string{}
+		for k, v := range stored.Annotations {
+			node.Annotations[k] = v
+		}
+		return true, &node, nil
+	})
+	fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		<-release
+		obj := action.(core.UpdateAction).GetObject().(*apiv1.Node)
+		lock.Lock()
+		stored = obj
+		lock.Unlock()
+		updatedNodes <- obj
+		return true, obj, nil
+	})
+	fakeClient.Fake.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.PodList{Items: []apiv1.Pod{*BuildTestPod("p1", 100, 0)}}, nil
+	})
+	err := drainNode(n1, []*apiv1.Pod{}, fakeClient, createEventRecorder(fakeClient), 20, 10*time.Millisecond)
+	assert.True(t, IsCordonTimeout(err))
+	assert.Contains(t, err.Error(), n1.Name)
+
+	// The delayed update lands and the taint is released again.
+	close(release)
+	tainted := <-updatedNodes
+	assert.True(t, hasToBeDeletedTaint(t, tainted))
+	released := <-updatedNodes
+	assert.False(t, hasToBeDeletedTaint(t, released))
+}
+
+func TestDrainNodeDefaultCordonTimeout(t *testing.T) {
+	fakeClient := &fake.Clientset{}
+	n1 := BuildTestNode("n1", 1000, 1000)
+	fakeClient.Fake.AddReactor("get", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		return true, n1, nil
+	})
+	fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		return true, action.(core.UpdateAction).GetObject(), nil
+	})
+	fakeClient.Fake.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.PodList{Items: []apiv1.Pod{*BuildTestPod("p1", 100, 0)}}, nil
+	})
+	err := drainNode(n1, []*apiv1.Pod{}, fakeClient, createEventRecorder(fakeClient), 20, 0)
+	assert.NoError(t, err)
+}
+
+func TestDrainNodeAlreadyDrained(t *testing.T) {
+	fakeClient := &fake.Clientset{}
+	n1 := BuildTestNode("n1", 1000, 1000)
+	ds := BuildTestPod("ds", 100, 0)
+	ds.Annotations = map[string]string{
+		apiv1.CreatedByAnnotation: "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"DaemonSet\",\"namespace\":\"default\",\"name\":\"ds\"}}",
+	}
+	fakeClient.Fake.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.PodList{Items: []apiv1.Pod{*ds}}, nil
+	})
+	fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		t.Errorf("unexpected node update")
+		return true, action.(core.UpdateAction).GetObject(), nil
+	})
+	err := drainNode(n1, []*apiv1.Pod{}, fakeClient, createEventRecorder(fakeClient), 20, 0)
+	assert.NoError(t, err)
+}
+
+func TestPodGracePeriod(t *testing.T) {
+	withPreStop := func(name string, terminationGracePeriod int64) *apiv1.Pod {
+		pod := BuildTestPod(name, 100, 0)
+		pod.Spec.TerminationGracePeriodSeconds = &terminationGracePeriod
+		pod.Spec.Containers[0].Lifecycle = &apiv1.Lifecycle{
+			PreStop: &apiv1.Handler{Exec: &apiv1.ExecAction{Command: []string{"sleep", "5"}}},
+		}
+		return pod
+	}
+	assert.Equal(t, int64(20), podGracePeriod(BuildTestPod("plain", 100, 0), 20))
+	assert.Equal(t, int64(30), podGracePeriod(withPreStop("short-hook", 10), 20))
+	assert.Equal(t, int64(40), podGracePeriod(withPreStop("long-hook", 600), 20))
+
+	mesh := BuildTestPod("mesh", 100, 0)
+	mesh.Spec.Containers = append(mesh.Spec.Containers, apiv1.Container{Name: "envoy"})
+	mesh.Annotations = map[string]string{drain.PreStopGraceAnnotation: "15s"}
+	assert.Equal(t, int64(35), podGracePeriod(mesh, 20))
+	mesh.Annotations[drain.PreStopGraceAnnotation] = "soon"
+	assert.Equal(t, int64(20), podGracePeriod(mesh, 20))
+}
+
+func hasToBeDeletedTaint(t *testing.T, node *apiv1.Node) bool {
+	taints, err := apiv1.GetTaintsFromNodeAnnotations(node.Annotations)
+	assert.NoError(t, err)
+	for _, taint := range taints {
+		if taint.Key == ToBeDeletedTaint {
+			return true
+		}
+	}
+	return false
+}
+
 func TestCleanNodes(t *testing.T) {
 	updatedNodes := make(chan string, 10)
 	fakeClient := &fake.Clientset{}
@@ -151,6 +301,33 @@ func TestCleanNodes(t *testing.T) {
 	assert.Equal(t, n1.Name, getStringFromChan(updatedNodes))
 }
 
+func TestRevertPartialDrain(t *testing.T) {
+	updatedNodes := make(chan string, 10)
+	fakeClient := &fake.Clientset{}
+
+	n1 := BuildTestNode("n1", 1000, 1000)
+	addToBeDeletedTaint(n1)
+
+	fakeClient.Fake.AddReactor("get", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		return true, n1, nil
+	})
+	fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		update := action.(core.UpdateAction)
+		obj := update.GetObject().(*apiv1.Node)
+		updatedNodes <- obj.Name
+		return true, obj, nil
+	})
+	fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		t.Errorf("unexpected pod creation")
+		return true, nil, nil
+	})
+
+	err := revertPartialDrain(n1, fakeClient, createEventRecorder(fakeClient))
+	assert.NoError(t, err)
+	assert.Equal(t, n1.Name, getStringFromChan(updatedNodes))
+	assert.False(t, hasToBeDeletedTaint(t, n1))
+}
+
 func getStringFromChan(c chan string) string {
 	select {
 	case val := <-c:
@@ -159,3 +336,58 @@ func getStringFromChan(c chan string) string {
 		return "Nothing returned"
 	}
 }
+
+type testNodeGroup struct {
+	minSize    int
+	targetSize int
+}
+
+func (ng *testNodeGroup) MaxSize() int                    { return 10 }
+func (ng *testNodeGroup) MinSize() int                    { return ng.minSize }
+func (ng *testNodeGroup) TargetSize() (int, error)        { return ng.targetSize, nil }
+func (ng *testNodeGroup) IncreaseSize(delta int) error    { return nil }
+func (ng *testNodeGroup) DeleteNodes([]*apiv1.Node) error { return nil }
+func (ng *testNodeGroup) Id() string                      { return "ng" }
+func (ng *testNodeGroup) Debug() string                   { return "ng" }
+
+type testCloudProvider struct {
+	nodeGroups map[string]*testNodeGroup
+}
+
+func (cp *testCloudProvider) Name() string { return "test" }
+func (cp *testCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
+	result := []cloudprovider.NodeGroup{}
+	for _, ng := range cp.nodeGroups {
+		result = append(result, ng)
+	}
+	return result
+}
+func (cp *testCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	if ng, found := cp.nodeGroups[node.Name]; found {
+		return ng, nil
+	}
+	return nil, nil
+}
+
+func TestGetNodeDrainConstraint(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n3 := BuildTestNode("n3", 1000, 1000)
+	provider := &testCloudProvider{nodeGroups: map[string]*testNodeGroup{
+		"n1": {minSize: 1, targetSize: 3},
+		"n2": {minSize: 3, targetSize: 3},
+	}}
+
+	minSize, size, err := getNodeDrainConstraint(n1, provider)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, minSize)
+	assert.Equal(t, 3, size)
+
+	minSize, size, err = getNodeDrainConstraint(n2, provider)
+	assert.Equal(t, errNodeGroupMinSizeReached, err)
+	assert.Equal(t, 3, minSize)
+	assert.Equal(t, 3, size)
+
+	_, _, err = getNodeDrainConstraint(n3, provider)
+	assert.Equal(t, errNoNodeGroupConfig, err)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+// ErrNoViableNode is returned when none of the candidate nodes satisfies the required node affinity of a pod.
+var ErrNoViableNode = fmt.Errorf("no candidate node satisfies the required node affinity")
+
+// PodCanBeRescheduled checks the RequiredDuringSchedulingIgnoredDuringExecution node affinity of the pod
+// against the labels of candidateNodes and returns the first node the pod could land on. Other
+// scheduling constraints (resources, taints, selectors) are not checked.
+func PodCanBeRescheduled(pod *apiv1.Pod, candidateNodes []*apiv1.Node) (bool, *apiv1.Node, error) {
+	for _, node := range candidateNodes {
+		matches, err := matchesRequiredNodeAffinity(pod, node)
+		if err != nil {
+			return false, nil, err
+		}
+		if matches {
+			return true, node, nil
+		}
+	}
+	return false, nil, ErrNoViableNode
+}
+
+// matchesRequiredNodeAffinity checks whether node satisfies the required node affinity of the pod. Pods
+// without such affinity match every node. The node selector terms are ORed.
+func matchesRequiredNodeAffinity(pod *apiv1.Pod, node *apiv1.Node) (bool, error) {
+	affinity, err := apiv1.GetAffinityFromPodAnnotations(pod.Annotations)
+	if err != nil {
+		return false, fmt.Errorf("failed to get affinity of %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		selector, err := apiv1.NodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil {
+			return false, fmt.Errorf("invalid node affinity of %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
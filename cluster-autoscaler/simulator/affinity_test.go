@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "k8s.io/contrib/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ssdAffinity = `{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":[` +
+	`{"matchExpressions":[{"key":"disk","operator":"In","values":["ssd"]}]}]}}}`
+
+func TestPodCanBeRescheduled(t *testing.T) {
+	hdd := BuildTestNode("hdd", 1000, 2000000)
+	hdd.Labels = map[string]string{"disk": "hdd"}
+	ssd := BuildTestNode("ssd", 1000, 2000000)
+	ssd.Labels = map[string]string{"disk": "ssd"}
+
+	pod := BuildTestPod("p1", 100, 1000)
+	ok, node, err := PodCanBeRescheduled(pod, []*apiv1.Node{hdd, ssd})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, hdd, node)
+
+	pod.Annotations = map[string]string{apiv1.AffinityAnnotationKey: ssdAffinity}
+	ok, node, err = PodCanBeRescheduled(pod, []*apiv1.Node{hdd, ssd})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ssd, node)
+
+	ok, node, err = PodCanBeRescheduled(pod, []*apiv1.Node{hdd})
+	assert.Equal(t, ErrNoViableNode, err)
+	assert.False(t, ok)
+	assert.Nil(t, node)
+}
+
+func TestCheckClusterCapacityForDrainWithAffinity(t *testing.T) {
+	pod := BuildTestPod("p1", 100, 1000)
+	pod.Spec.NodeName = "n1"
+	pod.Annotations = map[string]string{apiv1.AffinityAnnotationKey: ssdAffinity}
+
+	hdd := BuildTestNode("hdd", 1000, 2000000)
+	hdd.Labels = map[string]string{"disk": "hdd"}
+	hddInfo := schedulercache.NewNodeInfo()
+	hddInfo.SetNode(hdd)
+
+	sufficient, unschedulable, err := CheckClusterCapacityForDrain(context.Background(),
+		[]*apiv1.Pod{pod}, []*schedulercache.NodeInfo{hddInfo})
+	assert.NoError(t, err)
+	assert.False(t, sufficient)
+	assert.Equal(t, []*apiv1.Pod{pod}, unschedulable)
+}
+
+func TestFindPlaceForNodeAffinity(t *testing.T) {
+	hdd := BuildTestNode("hdd", 1000, 2000000)
+	hdd.Labels = map[string]string{"disk": "hdd"}
+	ssd := BuildTestNode("ssd", 1000, 2000000)
+	ssd.Labels = map[string]string{"disk": "ssd"}
+	nodeInfos := map[string]*schedulercache.NodeInfo{
+		"hdd": schedulercache.NewNodeInfo(),
+		"ssd": schedulercache.NewNodeInfo(),
+	}
+	nodeInfos["hdd"].SetNode(hdd)
+	nodeInfos["ssd"].SetNode(ssd)
+	// No predicates, so only the affinity check restricts the placement.
+	predicateChecker := &PredicateChecker{}
+
+	pod := BuildTestPod("p1", 100, 1000)
+	pod.Annotations = map[string]string{apiv1.AffinityAnnotationKey: ssdAffinity}
+	newHints := make(map[string]string)
+	err := findPlaceFor("x", []*apiv1.Pod{pod}, []*apiv1.Node{hdd, ssd}, nodeInfos, predicateChecker,
+		make(map[string]string), newHints, NewUsageTracker(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "ssd", newHints["default/p1"])
+
+	err = findPlaceFor("ssd", []*apiv1.Pod{pod}, []*apiv1.Node{hdd, ssd}, nodeInfos, predicateChecker,
+		make(map[string]string), make(map[string]string), NewUsageTracker(), time.Now())
+	assert.Error(t, err)
+}
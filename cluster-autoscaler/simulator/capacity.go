@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// CheckClusterCapacityForDrain checks whether the free cpu and memory of the nodes other than the ones the pods
+// are running on is enough to host all podsToEvict. Pods are placed greedily in the given order on nodes matching
+// their required node affinity, each placed pod reducing the free capacity of its destination. Returns the pods
+// that don't fit anywhere.
+func CheckClusterCapacityForDrain(ctx context.Context, podsToEvict []*apiv1.Pod,
+	nodeInfos []*schedulercache.NodeInfo) (sufficient bool, unschedulablePods []*apiv1.Pod, err error) {
+
+	drainedNodes := make(map[string]bool)
+	for _, pod := range podsToEvict {
+		drainedNodes[pod.Spec.NodeName] = true
+	}
+
+	nodes := make([]*apiv1.Node, 0, len(nodeInfos))
+	free := make([]schedulercache.Resource, 0, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		if node == nil || node.Spec.Unschedulable || drainedNodes[node.Name] {
+			continue
+		}
+		allocatable := nodeInfo.AllocatableResource()
+		requested := nodeInfo.RequestedResource()
+		nodes = append(nodes, node)
+		free = append(free, schedulercache.Resource{
+			MilliCPU: allocatable.MilliCPU - requested.MilliCPU,
+			Memory:   allocatable.Memory - requested.Memory,
+		})
+	}
+
+	unschedulablePods = []*apiv1.Pod{}
+	for _, pod := range podsToEvict {
+		if err := ctx.Err(); err != nil {
+			return false, unschedulablePods, err
+		}
+		cpu, mem := getPodCpuAndMemRequest(pod)
+		placed := false
+		for i := range free {
+			if free[i].MilliCPU < cpu || free[i].Memory < mem {
+				continue
+			}
+			matches, err := matchesRequiredNodeAffinity(pod, nodes[i])
+			if err != nil {
+				return false, unschedulablePods, err
+			}
+			if matches {
+				free[i].MilliCPU -= cpu
+				free[i].Memory -= mem
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			unschedulablePods = append(unschedulablePods, pod)
+		}
+	}
+	return len(unschedulablePods) == 0, unschedulablePods, nil
+}
+
+// ComputeClusterUtilizationAfterDrain returns the cpu and memory utilization, in percent, of the nodes other
+// than node after pods (usually the ones running on node) are rescheduled onto them. Utilization is the
+// sum of requests divided by the sum of allocatable resources. If no capacity remains the utilization
+// is 100 for any non-zero request.
+func ComputeClusterUtilizationAfterDrain(node *apiv1.Node, pods []*apiv1.Pod,
+	allNodeInfos []*schedulercache.NodeInfo) (cpu, memory float64) {
+
+	var allocatable, requested schedulercache.Resource
+	for _, nodeInfo := range allNodeInfos {
+		if nodeInfo.Node() == nil || nodeInfo.Node().Name == node.Name {
+			continue
+		}
+		allocatable.MilliCPU += nodeInfo.AllocatableResource().MilliCPU
+		allocatable.Memory += nodeInfo.AllocatableResource().Memory
+		requested.MilliCPU += nodeInfo.RequestedResource().MilliCPU
+		requested.Memory += nodeInfo.RequestedResource().Memory
+	}
+	for _, pod := range pods {
+		podCpu, podMem := getPodCpuAndMemRequest(pod)
+		requested.MilliCPU += podCpu
+		requested.Memory += podMem
+	}
+	return utilizationPercent(requested.MilliCPU, allocatable.MilliCPU), utilizationPercent(requested.Memory, allocatable.Memory)
+}
+
+func utilizationPercent(requested, allocatable int64) float64 {
+	if allocatable == 0 {
+		if requested == 0 {
+			return 0
+		}
+		return 100
+	}
+	return 100 * float64(requested) / float64(allocatable)
+}
+
+// getPodCpuAndMemRequest returns the cpu (in millicores) and memory (in bytes) requested by all containers of the pod.
+func getPodCpuAndMemRequest(pod *apiv1.Pod) (int64, int64) {
+	var cpu, mem int64
+	for _, container := range pod.Spec.Containers {
+		if value, found := container.Resources.Requests[apiv1.ResourceCPU]; found {
+			cpu += value.MilliValue()
+		}
+		if value, found := container.Resources.Requests[apiv1.ResourceMemory]; found {
+			mem += value.Value()
+		}
+	}
+	return cpu, mem
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	. "k8s.io/contrib/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckClusterCapacityForDrain(t *testing.T) {
+	p1 := BuildTestPod("p1", 600, 500000)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 600, 500000)
+	p2.Spec.NodeName = "n1"
+	p3 := BuildTestPod("p3", 500, 500000)
+	p3.Spec.NodeName = "n2"
+
+	n1 := BuildTestNode("n1", 2000, 2000000)
+	n2 := BuildTestNode("n2", 1000, 2000000)
+	n3 := BuildTestNode("n3", 1000, 2000000)
+	ni1 := schedulercache.NewNodeInfo(p1, p2)
+	ni1.SetNode(n1)
+	ni2 := schedulercache.NewNodeInfo(p3)
+	ni2.SetNode(n2)
+	ni3 := schedulercache.NewNodeInfo()
+	ni3.SetNode(n3)
+
+	// n2 has only 500m cpu free, so p1 lands on n3 and p2 fits nowhere.
+	sufficient, unschedulable, err := CheckClusterCapacityForDrain(context.Background(),
+		[]*apiv1.Pod{p1, p2}, []*schedulercache.NodeInfo{ni1, ni2, ni3})
+	assert.NoError(t, err)
+	assert.False(t, sufficient)
+	assert.Equal(t, []*apiv1.Pod{p2}, unschedulable)
+
+	sufficient, unschedulable, err = CheckClusterCapacityForDrain(context.Background(),
+		[]*apiv1.Pod{p3}, []*schedulercache.NodeInfo{ni1, ni2, ni3})
+	assert.NoError(t, err)
+	assert.True(t, sufficient)
+	assert.Empty(t, unschedulable)
+}
+
+func TestComputeClusterUtilizationAfterDrain(t *testing.T) {
+	p1 := BuildTestPod("p1", 500, 500000)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 250, 250000)
+	p2.Spec.NodeName = "n2"
+
+	n1 := BuildTestNode("n1", 1000, 1000000)
+	n2 := BuildTestNode("n2", 1000, 2000000)
+	n3 := BuildTestNode("n3", 1000, 2000000)
+	ni1 := schedulercache.NewNodeInfo(p1)
+	ni1.SetNode(n1)
+	ni2 := schedulercache.NewNodeInfo(p2)
+	ni2.SetNode(n2)
+	ni3 := schedulercache.NewNodeInfo()
+	ni3.SetNode(n3)
+
+	cpu, mem := ComputeClusterUtilizationAfterDrain(n1, []*apiv1.Pod{p1}, []*schedulercache.NodeInfo{ni1, ni2, ni3})
+	assert.InDelta(t, 37.5, cpu, 0.01)
+	assert.InDelta(t, 18.75, mem, 0.01)
+
+	cpu, mem = ComputeClusterUtilizationAfterDrain(n1, []*apiv1.Pod{p1}, []*schedulercache.NodeInfo{ni1})
+	assert.Equal(t, 100.0, cpu)
+	assert.Equal(t, 100.0, mem)
+}
@@ -181,8 +181,18 @@ func findPlaceFor(removedNode string, pods []*apiv1.Pod, nodes []*apiv1.Node, no
 				glog.Warningf("No node in nodeInfo %s -> %v", nodename, nodeInfo)
 				return false
 			}
+			// Required node affinity is checked even if the predicates don't cover it.
+			matches, err := matchesRequiredNodeAffinity(pod, nodeInfo.Node())
+			if err != nil {
+				glog.Warningf("Failed to check node affinity of %s/%s: %v", pod.Namespace, pod.Name, err)
+				return false
+			}
+			if !matches {
+				glog.V(4).Infof("Node %s doesn't match node affinity of %s/%s", nodename, pod.Namespace, pod.Name)
+				return false
+			}
 			nodeInfo.Node().Status.Allocatable = nodeInfo.Node().Status.Capacity
-			err := predicateChecker.CheckPredicates(pod, nodeInfo)
+			err = predicateChecker.CheckPredicates(pod, nodeInfo)
 			glog.V(4).Infof("Evaluation %s for %s/%s -> %v", nodename, pod.Namespace, pod.Name, err)
 			if err == nil {
 				// TODO(mwielgus): Optimize it.
@@ -0,0 +1,79 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+
+	"k8s.io/contrib/cluster-autoscaler/utils/drain"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// ScaleDownDisabledAnnotation set to "true" excludes the node from scale down.
+	ScaleDownDisabledAnnotation = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+)
+
+// FindUnderutilizedNodes returns nodes whose cpu and memory utilization are both below the given thresholds.
+// Nodes with ScaleDownDisabledAnnotation and nodes running nothing but DaemonSet and mirror pods (which are
+// handled as empty nodes) are skipped.
+func FindUnderutilizedNodes(ctx context.Context, nodeInfos []*schedulercache.NodeInfo,
+	cpuThreshold, memThreshold float64) ([]*apiv1.Node, error) {
+
+	result := []*apiv1.Node{}
+	for _, nodeInfo := range nodeInfos {
+		if err := ctx.Err(); err != nil {
+			return []*apiv1.Node{}, err
+		}
+		node := nodeInfo.Node()
+		if node == nil || node.Annotations[ScaleDownDisabledAnnotation] == "true" {
+			continue
+		}
+		if onlyDaemonSetPods(nodeInfo.Pods()) {
+			continue
+		}
+		cpu, err := calculateUtilizationOfResource(node, nodeInfo, apiv1.ResourceCPU)
+		if err != nil {
+			glog.Warningf("Failed to calculate cpu utilization for %s: %v", node.Name, err)
+			continue
+		}
+		mem, err := calculateUtilizationOfResource(node, nodeInfo, apiv1.ResourceMemory)
+		if err != nil {
+			glog.Warningf("Failed to calculate memory utilization for %s: %v", node.Name, err)
+			continue
+		}
+		if cpu < cpuThreshold && mem < memThreshold {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+func onlyDaemonSetPods(pods []*apiv1.Pod) bool {
+	for _, pod := range pods {
+		if drain.IsMirrorPod(pod) {
+			continue
+		}
+		if refKind, err := drain.CreatorRefKind(pod); err != nil || refKind != "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	. "k8s.io/contrib/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindUnderutilizedNodes(t *testing.T) {
+	dsCreatedBy := "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"DaemonSet\"}}"
+
+	buildNodeInfo := func(node *apiv1.Node, pods ...*apiv1.Pod) *schedulercache.NodeInfo {
+		nodeInfo := schedulercache.NewNodeInfo(pods...)
+		nodeInfo.SetNode(node)
+		return nodeInfo
+	}
+	lowCpu := BuildTestNode("low-cpu", 1000, 1000000)
+	lowBoth := BuildTestNode("low-both", 1000, 1000000)
+	disabled := BuildTestNode("disabled", 1000, 1000000)
+	disabled.Annotations = map[string]string{ScaleDownDisabledAnnotation: "true"}
+	dsOnly := BuildTestNode("ds-only", 1000, 1000000)
+	dsPod := BuildTestPod("ds", 100, 100000)
+	dsPod.Annotations = map[string]string{apiv1.CreatedByAnnotation: dsCreatedBy}
+
+	nodeInfos := []*schedulercache.NodeInfo{
+		buildNodeInfo(lowCpu, BuildTestPod("p1", 100, 800000)),
+		buildNodeInfo(lowBoth, BuildTestPod("p2", 100, 100000)),
+		buildNodeInfo(disabled, BuildTestPod("p3", 100, 100000)),
+		buildNodeInfo(dsOnly, dsPod),
+	}
+	nodes, err := FindUnderutilizedNodes(context.Background(), nodeInfos, 0.5, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Node{lowBoth}, nodes)
+}
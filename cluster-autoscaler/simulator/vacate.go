@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/kubelet/qos"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/golang/glog"
+)
+
+// PredicateFn checks whether the pod can be placed on the node described by nodeInfo.
+// PredicateChecker.CheckPredicates satisfies it.
+type PredicateFn func(pod *apiv1.Pod, nodeInfo *schedulercache.NodeInfo) error
+
+// CanNodeBeVacated checks whether every Guaranteed pod running on nodeToVacate fits on one of the other
+// nodes. Pods that found a place are accounted on their destination so that subsequent pods don't
+// reuse the same capacity. Returns true only if all pods fit, together with the names of pods
+// that don't fit anywhere.
+func CanNodeBeVacated(ctx context.Context, nodeInfos []*schedulercache.NodeInfo, nodeToVacate *apiv1.Node,
+	predicateFn PredicateFn) (bool, []string, error) {
+
+	var podsToMove []*apiv1.Pod
+	destinations := make([]*schedulercache.NodeInfo, 0, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		if nodeInfo.Node().Name == nodeToVacate.Name {
+			for _, pod := range nodeInfo.Pods() {
+				if qos.GetPodQOS(pod) == qos.Guaranteed {
+					podsToMove = append(podsToMove, pod)
+				}
+			}
+			continue
+		}
+		if nodeInfo.Node().Spec.Unschedulable {
+			continue
+		}
+		destinations = append(destinations, nodeInfo)
+	}
+
+	unplaced := []string{}
+	for _, podptr := range podsToMove {
+		if err := ctx.Err(); err != nil {
+			return false, unplaced, err
+		}
+		newpod := *podptr
+		newpod.Spec.NodeName = ""
+		pod := &newpod
+
+		foundPlace := false
+		for i, nodeInfo := range destinations {
+			if err := predicateFn(pod, nodeInfo); err != nil {
+				glog.V(4).Infof("Evaluation %s for %s/%s -> %v", nodeInfo.Node().Name, pod.Namespace, pod.Name, err)
+				continue
+			}
+			newNodeInfo := schedulercache.NewNodeInfo(append(nodeInfo.Pods(), pod)...)
+			if err := newNodeInfo.SetNode(nodeInfo.Node()); err != nil {
+				return false, unplaced, fmt.Errorf("failed to set node %s: %v", nodeInfo.Node().Name, err)
+			}
+			destinations[i] = newNodeInfo
+			foundPlace = true
+			break
+		}
+		if !foundPlace {
+			unplaced = append(unplaced, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+		}
+	}
+	return len(unplaced) == 0, unplaced, nil
+}
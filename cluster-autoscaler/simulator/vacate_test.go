@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	. "k8s.io/contrib/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildGuaranteedPod(name string, cpu int64, mem int64) *apiv1.Pod {
+	pod := BuildTestPod(name, cpu, mem)
+	pod.Spec.Containers[0].Resources.Limits = pod.Spec.Containers[0].Resources.Requests
+	return pod
+}
+
+func TestCanNodeBeVacated(t *testing.T) {
+	g1 := buildGuaranteedPod("g1", 600, 500000)
+	g2 := buildGuaranteedPod("g2", 600, 500000)
+	burstable := BuildTestPod("b1", 900, 500000)
+
+	node1 := BuildTestNode("n1", 1000, 2000000)
+	node2 := BuildTestNode("n2", 1000, 2000000)
+	node3 := BuildTestNode("n3", 1000, 2000000)
+
+	buildNodeInfos := func(pods ...*apiv1.Pod) []*schedulercache.NodeInfo {
+		ni1 := schedulercache.NewNodeInfo(pods...)
+		ni1.SetNode(node1)
+		ni2 := schedulercache.NewNodeInfo()
+		ni2.SetNode(node2)
+		ni3 := schedulercache.NewNodeInfo()
+		ni3.SetNode(node3)
+		return []*schedulercache.NodeInfo{ni1, ni2, ni3}
+	}
+	predicateFn := NewTestPredicateChecker().CheckPredicates
+
+	// Burstable pods are not checked.
+	ok, unplaced, err := CanNodeBeVacated(context.Background(), buildNodeInfos(g1, g2, burstable), node1, predicateFn)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, unplaced)
+
+	// The second Guaranteed pod must not reuse the capacity taken by the first one.
+	nodeInfos := buildNodeInfos(g1, g2)[:2]
+	ok, unplaced, err = CanNodeBeVacated(context.Background(), nodeInfos, node1, predicateFn)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"default/g2"}, unplaced)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = CanNodeBeVacated(ctx, buildNodeInfos(g1), node1, predicateFn)
+	assert.Error(t, err)
+}
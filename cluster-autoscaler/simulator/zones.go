@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+const (
+	// LabelTopologyZone is the zone label set by newer kubelets. Older ones only set
+	// metav1.LabelZoneFailureDomain.
+	LabelTopologyZone = "topology.kubernetes.io/zone"
+)
+
+// IsNodeZoneSingleton checks whether node is the only one in its zone among allNodes, which may or
+// may not include node itself. Returns the zone of the node as well. Nodes without a zone label are
+// never reported as singletons.
+func IsNodeZoneSingleton(node *apiv1.Node, allNodes []*apiv1.Node) (bool, string) {
+	zone := getNodeZone(node)
+	if zone == "" {
+		return false, ""
+	}
+	for _, other := range allNodes {
+		if other.Name != node.Name && getNodeZone(other) == zone {
+			return false, zone
+		}
+	}
+	return true, zone
+}
+
+// GetZonePinnedPods returns pods with a persistent volume in the zone of node. Such volumes (e.g.
+// single-zone persistent disks) can't be attached elsewhere, so the pods may only be rescheduled
+// within that zone. Claims that are missing or not bound yet are ignored.
+func GetZonePinnedPods(ctx context.Context, client client.Interface, node *apiv1.Node,
+	pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	zone := getNodeZone(node)
+	if zone == "" {
+		return result, nil
+	}
+	for _, pod := range pods {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			volumeZone, err := getClaimZone(client, pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+			if err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			if volumeZone == zone {
+				result = append(result, pod)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// getClaimZone returns the zone of the volume bound to the claim, or "" if it has none.
+func getClaimZone(client client.Interface, namespace, claimName string) (string, error) {
+	claim, err := client.Core().PersistentVolumeClaims(namespace).Get(claimName)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get claim %s/%s: %v", namespace, claimName, err)
+	}
+	if claim.Spec.VolumeName == "" {
+		return "", nil
+	}
+	volume, err := client.Core().PersistentVolumes().Get(claim.Spec.VolumeName)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get volume %s: %v", claim.Spec.VolumeName, err)
+	}
+	return getZone(volume.Labels), nil
+}
+
+func getNodeZone(node *apiv1.Node) string {
+	return getZone(node.Labels)
+}
+
+func getZone(objectLabels map[string]string) string {
+	if zone := objectLabels[LabelTopologyZone]; zone != "" {
+		return zone
+	}
+	return objectLabels[metav1.LabelZoneFailureDomain]
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	. "k8s.io/contrib/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNodeZoneSingleton(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000000)
+	n1.Labels = map[string]string{LabelTopologyZone: "zone-a"}
+	n2 := BuildTestNode("n2", 1000, 1000000)
+	n2.Labels = map[string]string{metav1.LabelZoneFailureDomain: "zone-a"}
+	n3 := BuildTestNode("n3", 1000, 1000000)
+	n3.Labels = map[string]string{LabelTopologyZone: "zone-b"}
+	noZone := BuildTestNode("no-zone", 1000, 1000000)
+	allNodes := []*apiv1.Node{n1, n2, n3, noZone}
+
+	singleton, zone := IsNodeZoneSingleton(n1, allNodes)
+	assert.False(t, singleton)
+	assert.Equal(t, "zone-a", zone)
+
+	singleton, zone = IsNodeZoneSingleton(n3, allNodes)
+	assert.True(t, singleton)
+	assert.Equal(t, "zone-b", zone)
+
+	singleton, zone = IsNodeZoneSingleton(noZone, allNodes)
+	assert.False(t, singleton)
+	assert.Equal(t, "", zone)
+}
+
+func TestGetZonePinnedPods(t *testing.T) {
+	node := BuildTestNode("n1", 1000, 1000000)
+	node.Labels = map[string]string{LabelTopologyZone: "zone-a"}
+	buildVolume := func(name, zone string) *apiv1.PersistentVolume {
+		return &apiv1.PersistentVolume{
+			ObjectMeta: apiv1.ObjectMeta{Name: name, Labels: map[string]string{metav1.LabelZoneFailureDomain: zone}},
+		}
+	}
+	buildClaim := func(name, volumeName string) *apiv1.PersistentVolumeClaim {
+		return &apiv1.PersistentVolumeClaim{
+			ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       apiv1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		}
+	}
+	buildPod := func(name, claimName string) *apiv1.Pod {
+		pod := BuildTestPod(name, 100, 1000)
+		pod.Spec.Volumes = []apiv1.Volume{{
+			Name: "data",
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			},
+		}}
+		return pod
+	}
+	fakeClient := fake.NewSimpleClientset(
+		buildVolume("pv-a", "zone-a"), buildVolume("pv-b", "zone-b"),
+		buildClaim("claim-a", "pv-a"), buildClaim("claim-b", "pv-b"), buildClaim("unbound", ""))
+	pinned := buildPod("pinned", "claim-a")
+	otherZone := buildPod("other-zone", "claim-b")
+	unbound := buildPod("unbound", "unbound")
+	missing := buildPod("missing", "missing")
+	plain := BuildTestPod("plain", 100, 1000)
+
+	pods, err := GetZonePinnedPods(context.Background(), fakeClient, node,
+		[]*apiv1.Pod{pinned, otherZone, unbound, missing, plain})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{pinned}, pods)
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/contrib/cluster-autoscaler/cloudprovider"
 	"k8s.io/contrib/cluster-autoscaler/expander"
 	"k8s.io/contrib/cluster-autoscaler/simulator"
+	"k8s.io/contrib/cluster-autoscaler/utils/drain"
 
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
 	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
@@ -62,6 +63,14 @@ type AutoscalingContext struct {
 	// MaxGratefulTerminationSec is maximum number of seconds scale down waits for pods to terminante before
 	// removing the node from cloud provider.
 	MaxGratefulTerminationSec int
+	// CordonTimeout is the maximum time scale down waits for the node to be marked as unschedulable.
+	// Non-positive values mean defaultCordonTimeout.
+	CordonTimeout time.Duration
+	// ApprovalRequired makes scale down file a change with ChangeAdvisoryBoard and wait for its approval
+	// before a node with pods is cordoned and drained.
+	ApprovalRequired bool
+	// ChangeAdvisoryBoard approves drains if ApprovalRequired is set.
+	ChangeAdvisoryBoard drain.ChangeAdvisoryBoardClient
 }
 
 // GetAllNodesAvailableTime returns time when the newest node became available for scheduler.
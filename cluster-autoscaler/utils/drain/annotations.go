@@ -0,0 +1,166 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// SafeToEvictAfterAnnotation holds an RFC3339 time before which the pod must not be evicted.
+	SafeToEvictAfterAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict-after"
+	// ScheduleByAnnotation set to "manual" marks a pod that was placed on its node by hand.
+	ScheduleByAnnotation = "scheduler.alpha.kubernetes.io/schedule-by"
+	// DrainPriorityAnnotation set to "high" or "low" makes the pod evicted before or after other pods.
+	DrainPriorityAnnotation = "cluster-autoscaler.kubernetes.io/drain-priority"
+	// DrainTimeoutAnnotation holds a duration (e.g. "600s") the pod needs to be drained, see GetEffectiveDrainTimeout.
+	DrainTimeoutAnnotation = "cluster-autoscaler.kubernetes.io/drain-timeout"
+	// LongLivedConnectionsAnnotation set to "true" marks a pod serving long-lived connections, e.g. gRPC streams.
+	LongLivedConnectionsAnnotation = "cluster-autoscaler.kubernetes.io/long-lived-connections"
+	// NetworkCriticalAnnotation set to "true" marks a pod other pods depend on for connectivity, e.g. a proxy.
+	NetworkCriticalAnnotation = "cluster-autoscaler.kubernetes.io/network-critical"
+
+	// DefaultLongLivedConnectionDrainDelay is used when DrainOptions.LongLivedConnectionDrainDelay is not set.
+	DefaultLongLivedConnectionDrainDelay = 30 * time.Second
+)
+
+// GetEvictionHold returns the time until which the pod asked not to be evicted, as declared in
+// SafeToEvictAfterAnnotation. The second return value is false if the pod has no such annotation.
+func GetEvictionHold(pod *apiv1.Pod) (time.Time, bool, error) {
+	value, found := pod.ObjectMeta.Annotations[SafeToEvictAfterAnnotation]
+	if !found {
+		return time.Time{}, false, nil
+	}
+	holdUntil, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse %s annotation of %s/%s: %v",
+			SafeToEvictAfterAnnotation, pod.Namespace, pod.Name, err)
+	}
+	return holdUntil, true, nil
+}
+
+// GetManuallyScheduledPods returns pods with ScheduleByAnnotation set to "manual". They were
+// probably placed by hand to work around a scheduling problem and may not land anywhere else.
+func GetManuallyScheduledPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if isManuallyScheduled(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func isManuallyScheduled(pod *apiv1.Pod) bool {
+	return pod.ObjectMeta.Annotations[ScheduleByAnnotation] == "manual"
+}
+
+// GetEffectiveDrainTimeout returns the drain timeout for the pod: the duration from DrainTimeoutAnnotation
+// if it is longer than globalTimeout, globalTimeout otherwise. Pods can only extend the drain window,
+// never shorten it. An invalid annotation is logged and ignored, see ValidateDrainTimeoutAnnotation.
+func GetEffectiveDrainTimeout(pod *apiv1.Pod, globalTimeout time.Duration) time.Duration {
+	timeout, err := parseDrainTimeout(pod)
+	if err != nil {
+		glog.Warningf("Ignoring drain timeout: %v", err)
+		return globalTimeout
+	}
+	if timeout > globalTimeout {
+		return timeout
+	}
+	return globalTimeout
+}
+
+// ValidateDrainTimeoutAnnotation returns an error if the pod has a DrainTimeoutAnnotation that is not
+// a valid non-negative duration.
+func ValidateDrainTimeoutAnnotation(pod *apiv1.Pod) error {
+	_, err := parseDrainTimeout(pod)
+	return err
+}
+
+func parseDrainTimeout(pod *apiv1.Pod) (time.Duration, error) {
+	value, found := pod.ObjectMeta.Annotations[DrainTimeoutAnnotation]
+	if !found {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s annotation of %s/%s: %v",
+			DrainTimeoutAnnotation, pod.Namespace, pod.Name, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("negative %s annotation of %s/%s: %s", DrainTimeoutAnnotation, pod.Namespace, pod.Name, value)
+	}
+	return timeout, nil
+}
+
+// GetLongLivedConnectionPods returns pods with LongLivedConnectionsAnnotation set to "true" or with a
+// container named "grpc-*". Their clients need extra time to reconnect elsewhere, see GetEvictionDelay.
+func GetLongLivedConnectionPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if hasLongLivedConnections(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// GetEvictionDelay returns how long to wait before evicting the pod: opts.LongLivedConnectionDrainDelay
+// (or DefaultLongLivedConnectionDrainDelay if not set) for pods with long-lived connections, 0 otherwise.
+func GetEvictionDelay(pod *apiv1.Pod, opts DrainOptions) time.Duration {
+	if !hasLongLivedConnections(pod) {
+		return 0
+	}
+	if opts.LongLivedConnectionDrainDelay > 0 {
+		return opts.LongLivedConnectionDrainDelay
+	}
+	return DefaultLongLivedConnectionDrainDelay
+}
+
+func hasLongLivedConnections(pod *apiv1.Pod) bool {
+	if pod.ObjectMeta.Annotations[LongLivedConnectionsAnnotation] == "true" {
+		return true
+	}
+	for _, container := range pod.Spec.Containers {
+		if strings.HasPrefix(container.Name, "grpc-") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNetworkCriticalPods returns pods with NetworkCriticalAnnotation set to "true". Evicting them may
+// cut other pods off the network until they are running again.
+func GetNetworkCriticalPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if isNetworkCritical(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func isNetworkCritical(pod *apiv1.Pod) bool {
+	return pod.ObjectMeta.Annotations[NetworkCriticalAnnotation] == "true"
+}
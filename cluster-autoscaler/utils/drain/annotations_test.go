@@ -0,0 +1,102 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildAnnotatedPod(name string, annotations map[string]string) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestGetEvictionHold(t *testing.T) {
+	_, found, err := GetEvictionHold(buildAnnotatedPod("p1", nil))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	holdUntil, found, err := GetEvictionHold(buildAnnotatedPod("p2",
+		map[string]string{SafeToEvictAfterAnnotation: "2024-01-01T00:00:00Z"}))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), holdUntil.UTC())
+
+	_, _, err = GetEvictionHold(buildAnnotatedPod("p3",
+		map[string]string{SafeToEvictAfterAnnotation: "tomorrow"}))
+	assert.Error(t, err)
+}
+
+func TestGetManuallyScheduledPods(t *testing.T) {
+	manual := buildAnnotatedPod("manual", map[string]string{ScheduleByAnnotation: "manual"})
+	other := buildAnnotatedPod("other", map[string]string{ScheduleByAnnotation: "scheduler"})
+	plain := buildAnnotatedPod("plain", nil)
+
+	assert.Equal(t, []*apiv1.Pod{manual}, GetManuallyScheduledPods([]*apiv1.Pod{manual, other, plain}))
+}
+
+func TestGetEffectiveDrainTimeout(t *testing.T) {
+	global := 5 * time.Minute
+	longer := buildAnnotatedPod("longer", map[string]string{DrainTimeoutAnnotation: "600s"})
+	shorter := buildAnnotatedPod("shorter", map[string]string{DrainTimeoutAnnotation: "10s"})
+	invalid := buildAnnotatedPod("invalid", map[string]string{DrainTimeoutAnnotation: "ten minutes"})
+	negative := buildAnnotatedPod("negative", map[string]string{DrainTimeoutAnnotation: "-10m"})
+	plain := buildAnnotatedPod("plain", nil)
+
+	assert.Equal(t, 10*time.Minute, GetEffectiveDrainTimeout(longer, global))
+	assert.Equal(t, global, GetEffectiveDrainTimeout(shorter, global))
+	assert.Equal(t, global, GetEffectiveDrainTimeout(invalid, global))
+	assert.Equal(t, global, GetEffectiveDrainTimeout(plain, global))
+
+	assert.NoError(t, ValidateDrainTimeoutAnnotation(longer))
+	assert.NoError(t, ValidateDrainTimeoutAnnotation(plain))
+	assert.Error(t, ValidateDrainTimeoutAnnotation(invalid))
+	assert.Error(t, ValidateDrainTimeoutAnnotation(negative))
+}
+
+func TestGetLongLivedConnectionPods(t *testing.T) {
+	annotated := buildAnnotatedPod("annotated", map[string]string{LongLivedConnectionsAnnotation: "true"})
+	grpc := buildAnnotatedPod("grpc", nil)
+	grpc.Spec.Containers = []apiv1.Container{{Name: "sidecar"}, {Name: "grpc-server"}}
+	disabled := buildAnnotatedPod("disabled", map[string]string{LongLivedConnectionsAnnotation: "false"})
+	plain := buildAnnotatedPod("plain", nil)
+	plain.Spec.Containers = []apiv1.Container{{Name: "web"}}
+
+	assert.Equal(t, []*apiv1.Pod{annotated, grpc}, GetLongLivedConnectionPods([]*apiv1.Pod{annotated, grpc, disabled, plain}))
+
+	assert.Equal(t, DefaultLongLivedConnectionDrainDelay, GetEvictionDelay(grpc, DrainOptions{}))
+	assert.Equal(t, time.Minute, GetEvictionDelay(grpc, DrainOptions{LongLivedConnectionDrainDelay: time.Minute}))
+	assert.Equal(t, time.Duration(0), GetEvictionDelay(plain, DrainOptions{LongLivedConnectionDrainDelay: time.Minute}))
+}
+
+func TestGetNetworkCriticalPods(t *testing.T) {
+	critical := buildAnnotatedPod("critical", map[string]string{NetworkCriticalAnnotation: "true"})
+	other := buildAnnotatedPod("other", map[string]string{NetworkCriticalAnnotation: "false"})
+	plain := buildAnnotatedPod("plain", nil)
+
+	assert.Equal(t, []*apiv1.Pod{critical}, GetNetworkCriticalPods([]*apiv1.Pod{critical, other, plain}))
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/labels"
+
+	"github.com/golang/glog"
+)
+
+// GetHostnameAntiAffinityPods returns pods with a required pod anti-affinity on kubernetes.io/hostname
+// that can't all be placed on the remaining nodes: each of them needs its own node, so a pod is returned
+// when the pods matched by its anti-affinity outnumber the schedulable nodes it doesn't run on. Only
+// the given pods are considered as peers. Pods with an invalid affinity annotation are logged and skipped.
+func GetHostnameAntiAffinityPods(pods []*apiv1.Pod, nodes []*apiv1.Node) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		terms, err := hostnameAntiAffinityTerms(pod)
+		if err != nil {
+			glog.Warningf("Failed to get affinity of %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if len(terms) == 0 {
+			continue
+		}
+		peers := 0
+		for _, other := range pods {
+			if other == pod || matchesAnyTerm(pod, other, terms) {
+				peers++
+			}
+		}
+		candidates := 0
+		for _, node := range nodes {
+			if node.Name != pod.Spec.NodeName && !node.Spec.Unschedulable {
+				candidates++
+			}
+		}
+		if peers > candidates {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func hostnameAntiAffinityTerms(pod *apiv1.Pod) ([]apiv1.PodAffinityTerm, error) {
+	affinity, err := apiv1.GetAffinityFromPodAnnotations(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return nil, nil
+	}
+	terms := []apiv1.PodAffinityTerm{}
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey == metav1.LabelHostname {
+			terms = append(terms, term)
+		}
+	}
+	return terms, nil
+}
+
+// matchesAnyTerm checks whether other is selected by one of the anti-affinity terms of pod.
+func matchesAnyTerm(pod, other *apiv1.Pod, terms []apiv1.PodAffinityTerm) bool {
+	for _, term := range terms {
+		namespaces := term.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{pod.Namespace}
+		}
+		inNamespace := false
+		for _, namespace := range namespaces {
+			if namespace == other.Namespace {
+				inNamespace = true
+			}
+		}
+		if !inNamespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err == nil && selector.Matches(labels.Set(other.Labels)) {
+			return true
+		}
+	}
+	return false
+}
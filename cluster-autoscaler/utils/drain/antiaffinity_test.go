@@ -0,0 +1,47 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostnameAntiAffinityPods(t *testing.T) {
+	antiAffinity := `{"podAntiAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":[` +
+		`{"labelSelector":{"matchLabels":{"app":"db"}},"topologyKey":"kubernetes.io/hostname"}]}}`
+	buildPod := func(name string) *apiv1.Pod {
+		pod := buildReplicatedPod(name, map[string]string{"app": "db"})
+		pod.Annotations[apiv1.AffinityAnnotationKey] = antiAffinity
+		return pod
+	}
+	buildNode := func(name string, unschedulable bool) *apiv1.Node {
+		return &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: name}, Spec: apiv1.NodeSpec{Unschedulable: unschedulable}}
+	}
+	db0, db1, db2 := buildPod("db-0"), buildPod("db-1"), buildPod("db-2")
+	web := buildReplicatedPod("web", map[string]string{"app": "web"})
+	pods := []*apiv1.Pod{db0, db1, db2, web}
+
+	nodes := []*apiv1.Node{buildNode("node", false), buildNode("n1", false), buildNode("n2", false), buildNode("n3", false)}
+	assert.Empty(t, GetHostnameAntiAffinityPods(pods, nodes))
+
+	nodes = []*apiv1.Node{buildNode("node", false), buildNode("n1", false), buildNode("n2", false), buildNode("n3", true)}
+	assert.Equal(t, []*apiv1.Pod{db0, db1, db2}, GetHostnameAntiAffinityPods(pods, nodes))
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// maxAPIServerCheckInterval caps the back-off between API server checks.
+	maxAPIServerCheckInterval = time.Minute
+	// ControlPlaneMaintenanceAnnotation on the kube-system namespace holds the control plane maintenance
+	// window as two RFC3339 times separated by a slash, e.g. "2017-01-10T02:00:00Z/2017-01-10T04:00:00Z".
+	ControlPlaneMaintenanceAnnotation = "cluster-autoscaler.kubernetes.io/control-plane-maintenance"
+)
+
+// WaitForAPIServerReady polls the version endpoint of the API server until it responds, so that drains
+// pause while the control plane is unavailable (e.g. during an upgrade) instead of failing. The interval
+// between checks starts at checkInterval and doubles after each failure, up to a minute. An error
+// is returned when ctx is done first or checkInterval is not positive.
+func WaitForAPIServerReady(ctx context.Context, client client.Interface, checkInterval time.Duration) error {
+	return waitWithBackoff(ctx, checkInterval, func() error {
+		_, err := client.Discovery().ServerVersion()
+		return err
+	})
+}
+
+// waitWithBackoff calls check until it succeeds, doubling the interval after each failure.
+func waitWithBackoff(ctx context.Context, interval time.Duration, check func() error) error {
+	if interval <= 0 {
+		return fmt.Errorf("check interval must be positive, got %v", interval)
+	}
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		glog.V(2).Infof("API server not ready, retrying in %v: %v", interval, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("API server not ready: %v, last error: %v", ctx.Err(), err)
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > maxAPIServerCheckInterval {
+			interval = maxAPIServerCheckInterval
+		}
+	}
+}
+
+// IsControlPlaneMaintenance checks whether the current time is within the maintenance window declared in
+// ControlPlaneMaintenanceAnnotation and returns how long the maintenance still lasts. Drains should
+// pause during maintenance so as not to add to the disruption.
+func IsControlPlaneMaintenance(ctx context.Context, client client.Interface) (bool, time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return false, 0, err
+	}
+	namespace, err := client.Core().Namespaces().Get("kube-system")
+	if errors.IsNotFound(err) {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get kube-system namespace: %v", err)
+	}
+	window, found := namespace.Annotations[ControlPlaneMaintenanceAnnotation]
+	if !found {
+		return false, 0, nil
+	}
+	return inMaintenanceWindow(window, time.Now())
+}
+
+// inMaintenanceWindow parses a "<start>/<end>" window and checks whether now is within it.
+func inMaintenanceWindow(window string, now time.Time) (bool, time.Duration, error) {
+	parts := strings.Split(window, "/")
+	if len(parts) != 2 {
+		return false, 0, fmt.Errorf("invalid maintenance window %q: expected <start>/<end>", window)
+	}
+	start, err := time.Parse(time.RFC3339, parts[0])
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid start of maintenance window %q: %v", window, err)
+	}
+	end, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid end of maintenance window %q: %v", window, err)
+	}
+	if now.Before(start) || !now.Before(end) {
+		return false, 0, nil
+	}
+	return true, end.Sub(now), nil
+}
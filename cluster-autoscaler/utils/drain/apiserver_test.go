@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForAPIServerReady(t *testing.T) {
+	assert.NoError(t, WaitForAPIServerReady(context.Background(), fake.NewSimpleClientset(), time.Millisecond))
+}
+
+func TestWaitWithBackoff(t *testing.T) {
+	calls := 0
+	err := waitWithBackoff(context.Background(), time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = waitWithBackoff(ctx, time.Millisecond, func() error { return fmt.Errorf("connection refused") })
+	assert.Error(t, err)
+
+	calls = 0
+	err = waitWithBackoff(context.Background(), 0, func() error {
+		calls++
+		return fmt.Errorf("connection refused")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIsControlPlaneMaintenance(t *testing.T) {
+	window := time.Now().Add(-time.Hour).Format(time.RFC3339) + "/" + time.Now().Add(time.Hour).Format(time.RFC3339)
+	fakeClient := fake.NewSimpleClientset(&apiv1.Namespace{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "kube-system",
+			Annotations: map[string]string{ControlPlaneMaintenanceAnnotation: window},
+		},
+	})
+	maintenance, remaining, err := IsControlPlaneMaintenance(context.Background(), fakeClient)
+	assert.NoError(t, err)
+	assert.True(t, maintenance)
+	assert.True(t, remaining > 59*time.Minute && remaining <= time.Hour)
+
+	maintenance, _, err = IsControlPlaneMaintenance(context.Background(), fake.NewSimpleClientset())
+	assert.NoError(t, err)
+	assert.False(t, maintenance)
+}
+
+func TestInMaintenanceWindow(t *testing.T) {
+	window := "2017-01-10T02:00:00Z/2017-01-10T04:00:00Z"
+
+	inWindow, remaining, err := inMaintenanceWindow(window, time.Date(2017, 1, 10, 3, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, inWindow)
+	assert.Equal(t, 30*time.Minute, remaining)
+
+	inWindow, _, err = inMaintenanceWindow(window, time.Date(2017, 1, 10, 4, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.False(t, inWindow)
+
+	_, _, err = inMaintenanceWindow("tonight", time.Now())
+	assert.Error(t, err)
+	_, _, err = inMaintenanceWindow("2017-01-10T02:00:00Z/later", time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// ChangeRequest describes a drain submitted for approval to a change management system.
+type ChangeRequest struct {
+	NodeName string
+	// Pods are the namespace/name of the pods that would be evicted.
+	Pods   []string
+	Reason string
+}
+
+// ChangeAdvisoryBoardClient is implemented by integrations with ITSM tools (e.g. ServiceNow or Jira)
+// that track changes to the cluster.
+type ChangeAdvisoryBoardClient interface {
+	// RequestApproval files the change and returns its id in the external system.
+	RequestApproval(ctx context.Context, change ChangeRequest) (changeID string, err error)
+	// WaitForApproval blocks until the change is approved. An error is returned if it is rejected or
+	// ctx is done first.
+	WaitForApproval(ctx context.Context, changeID string) error
+}
+
+// RequestDrainApproval files a change for draining the node with board and waits for it to be approved.
+// It returns the id of the approved change.
+func RequestDrainApproval(ctx context.Context, board ChangeAdvisoryBoardClient, node *apiv1.Node, pods []*apiv1.Pod,
+	reason string) (string, error) {
+	if board == nil {
+		return "", fmt.Errorf("approval required to drain node %s but no change advisory board is configured", node.Name)
+	}
+	change := ChangeRequest{NodeName: node.Name, Pods: make([]string, 0, len(pods)), Reason: reason}
+	for _, pod := range pods {
+		change.Pods = append(change.Pods, pod.Namespace+"/"+pod.Name)
+	}
+	changeID, err := board.RequestApproval(ctx, change)
+	if err != nil {
+		return "", fmt.Errorf("failed to request approval to drain node %s: %v", node.Name, err)
+	}
+	if err := board.WaitForApproval(ctx, changeID); err != nil {
+		return "", fmt.Errorf("drain of node %s not approved in change %s: %v", node.Name, changeID, err)
+	}
+	return changeID, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockChangeAdvisoryBoard struct {
+	requested []ChangeRequest
+	rejected  bool
+}
+
+func (m *mockChangeAdvisoryBoard) RequestApproval(ctx context.Context, change ChangeRequest) (string, error) {
+	m.requested = append(m.requested, change)
+	return fmt.Sprintf("CHG%d", len(m.requested)), nil
+}
+
+func (m *mockChangeAdvisoryBoard) WaitForApproval(ctx context.Context, changeID string) error {
+	if m.rejected {
+		return fmt.Errorf("change %s rejected", changeID)
+	}
+	return nil
+}
+
+func TestRequestDrainApproval(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+	pods := []*apiv1.Pod{buildReplicatedPod("web", nil), buildReplicatedPod("db", nil)}
+	board := &mockChangeAdvisoryBoard{}
+
+	changeID, err := RequestDrainApproval(context.Background(), board, node, pods, "scale down")
+	assert.NoError(t, err)
+	assert.Equal(t, "CHG1", changeID)
+	assert.Equal(t, []ChangeRequest{{
+		NodeName: "node",
+		Pods:     []string{"default/web", "default/db"},
+		Reason:   "scale down",
+	}}, board.requested)
+
+	board.rejected = true
+	_, err = RequestDrainApproval(context.Background(), board, node, pods, "scale down")
+	assert.Error(t, err)
+
+	_, err = RequestDrainApproval(context.Background(), nil, node, pods, "scale down")
+	assert.Error(t, err)
+}
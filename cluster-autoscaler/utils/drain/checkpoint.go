@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+const (
+	// CheckpointFileAnnotation marks a pod that can checkpoint its progress to the given file.
+	CheckpointFileAnnotation = "cluster-autoscaler.kubernetes.io/checkpoint-file"
+	// CheckpointURLAnnotation holds the port and path, e.g. "8080/checkpoint", of the endpoint on the pod
+	// IP that makes the pod write a checkpoint when POSTed to.
+	CheckpointURLAnnotation = "cluster-autoscaler.kubernetes.io/checkpoint-url"
+
+	// checkpointTimeout bounds a checkpoint request.
+	checkpointTimeout = 30 * time.Second
+)
+
+// SupportsCheckpoint checks whether the pod declares CheckpointFileAnnotation and can be asked to
+// checkpoint through CheckpointURLAnnotation.
+func SupportsCheckpoint(pod *apiv1.Pod) bool {
+	return pod.ObjectMeta.Annotations[CheckpointFileAnnotation] != "" &&
+		pod.ObjectMeta.Annotations[CheckpointURLAnnotation] != ""
+}
+
+// TriggerPodCheckpoint sends a POST request to the checkpoint endpoint of the pod and waits for
+// an OK response, after which the pod can be safely evicted. The request always goes to the pod IP,
+// the annotation only selects the port and path, so pods can't make the autoscaler reach other
+// hosts. If httpClient is nil a client with a 30s timeout is used. The request is cancelled together
+// with ctx and after 30s at the latest.
+func TriggerPodCheckpoint(ctx context.Context, httpClient *http.Client, pod *apiv1.Pod) error {
+	url, err := checkpointURL(pod)
+	if err != nil {
+		return err
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: checkpointTimeout}
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+	defer cancel()
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid checkpoint url of %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("checkpoint of %s/%s failed with status %s", pod.Namespace, pod.Name, resp.Status)
+	}
+	return nil
+}
+
+// checkpointURL builds the checkpoint endpoint of the pod from its IP and CheckpointURLAnnotation.
+func checkpointURL(pod *apiv1.Pod) (string, error) {
+	value := pod.ObjectMeta.Annotations[CheckpointURLAnnotation]
+	if value == "" {
+		return "", fmt.Errorf("pod %s/%s has no %s annotation", pod.Namespace, pod.Name, CheckpointURLAnnotation)
+	}
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod %s/%s has no IP", pod.Namespace, pod.Name)
+	}
+	port, path := value, "/"
+	if i := strings.Index(value, "/"); i >= 0 {
+		port, path = value[:i], value[i:]
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		return "", fmt.Errorf("invalid %s annotation of %s/%s: %q doesn't start with a port",
+			CheckpointURLAnnotation, pod.Namespace, pod.Name, value)
+	}
+	return "http://" + pod.Status.PodIP + ":" + port + path, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerPodCheckpoint(t *testing.T) {
+	checkpoints := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		checkpoints++
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	parts := strings.Split(serverURL.Host, ":")
+	buildPod := func(name, endpoint string) *apiv1.Pod {
+		pod := buildAnnotatedPod(name, map[string]string{CheckpointURLAnnotation: endpoint})
+		pod.Status.PodIP = parts[0]
+		return pod
+	}
+
+	pod := buildPod("batch", parts[1]+"/checkpoint")
+	pod.Annotations[CheckpointFileAnnotation] = "/data/checkpoint"
+	assert.True(t, SupportsCheckpoint(pod))
+	assert.NoError(t, TriggerPodCheckpoint(context.Background(), nil, pod))
+	assert.Equal(t, 1, checkpoints)
+
+	broken := buildPod("broken", parts[1]+"/broken")
+	assert.False(t, SupportsCheckpoint(broken))
+	assert.Error(t, TriggerPodCheckpoint(context.Background(), &http.Client{}, broken))
+
+	assert.Error(t, TriggerPodCheckpoint(context.Background(), nil, buildAnnotatedPod("plain", nil)))
+
+	// Only the pod IP can be reached.
+	for _, endpoint := range []string{server.URL + "/checkpoint", "169.254.169.254/latest", "0/checkpoint", ""} {
+		assert.Error(t, TriggerPodCheckpoint(context.Background(), nil, buildPod("other-host", endpoint)), endpoint)
+	}
+	assert.Equal(t, 1, checkpoints)
+	noIP := buildPod("no-ip", parts[1]+"/checkpoint")
+	noIP.Status.PodIP = ""
+	assert.Error(t, TriggerPodCheckpoint(context.Background(), nil, noIP))
+}
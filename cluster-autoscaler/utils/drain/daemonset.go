@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// GetDaemonSetPodsOnNode lists the DaemonSet-managed pods running on the node. After a complete drain
+// these, together with mirror pods, are the only pods expected to remain on the node.
+func GetDaemonSetPodsOnNode(ctx context.Context, client client.Interface, nodeName string) ([]*apiv1.Pod, error) {
+	if err := ctx.Err(); err != nil {
+		return []*apiv1.Pod{}, err
+	}
+	pods, err := listPodsOnNode(client, nodeName)
+	if err != nil {
+		return []*apiv1.Pod{}, err
+	}
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		refKind, err := CreatorRefKind(pod)
+		if err != nil {
+			return []*apiv1.Pod{}, fmt.Errorf("failed to obtain refkind for %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if refKind == "DaemonSet" {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+// IsNodeAlreadyDrained checks whether only DaemonSet and mirror pods remain on the node, in which
+// case there is nothing left to drain.
+func IsNodeAlreadyDrained(ctx context.Context, client client.Interface, nodeName string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	pods, err := listPodsOnNode(client, nodeName)
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods {
+		if IsMirrorPod(pod) {
+			continue
+		}
+		refKind, err := CreatorRefKind(pod)
+		if err != nil {
+			return false, fmt.Errorf("failed to obtain refkind for %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if refKind != "DaemonSet" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
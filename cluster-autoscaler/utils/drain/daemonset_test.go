@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+	kubelettypes "k8s.io/kubernetes/pkg/kubelet/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDaemonSetPodsOnNode(t *testing.T) {
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	rsPod := buildReplicatedPod("rs", nil)
+
+	fakeClient := fake.NewSimpleClientset(dsPod, rsPod)
+	pods, err := GetDaemonSetPodsOnNode(context.Background(), fakeClient, "node")
+	assert.NoError(t, err)
+	if assert.Len(t, pods, 1) {
+		assert.Equal(t, "ds", pods[0].Name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GetDaemonSetPodsOnNode(ctx, fakeClient, "node")
+	assert.Error(t, err)
+}
+
+func TestIsNodeAlreadyDrained(t *testing.T) {
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	mirrorPod := buildReplicatedPod("mirror", nil)
+	mirrorPod.Annotations = map[string]string{kubelettypes.ConfigMirrorAnnotationKey: "something"}
+	rsPod := buildReplicatedPod("rs", nil)
+
+	drained, err := IsNodeAlreadyDrained(context.Background(), fake.NewSimpleClientset(dsPod, mirrorPod), "node")
+	assert.NoError(t, err)
+	assert.True(t, drained)
+
+	drained, err = IsNodeAlreadyDrained(context.Background(), fake.NewSimpleClientset(dsPod, rsPod), "node")
+	assert.NoError(t, err)
+	assert.False(t, drained)
+}
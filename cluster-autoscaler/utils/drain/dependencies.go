@@ -0,0 +1,108 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// GetPodsWithMissingDependencies returns pods referencing a ConfigMap or Secret, in a volume or an
+// environment variable, that no longer exists. Such pods are already broken and would not start after
+// being rescheduled either, so evicting them is safe.
+func GetPodsWithMissingDependencies(ctx context.Context, client client.Interface,
+	pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	exists := map[string]bool{}
+	for _, pod := range pods {
+		configMaps, secrets := podDependencies(pod)
+		missing := false
+		for _, name := range configMaps {
+			found, err := dependencyExists(ctx, exists, "configmap", pod.Namespace, name, func() error {
+				_, err := client.Core().ConfigMaps(pod.Namespace).Get(name)
+				return err
+			})
+			if err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			missing = missing || !found
+		}
+		for _, name := range secrets {
+			found, err := dependencyExists(ctx, exists, "secret", pod.Namespace, name, func() error {
+				_, err := client.Core().Secrets(pod.Namespace).Get(name)
+				return err
+			})
+			if err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			missing = missing || !found
+		}
+		if missing {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+// dependencyExists checks whether the object fetched by get exists, caching the result in exists.
+func dependencyExists(ctx context.Context, exists map[string]bool, kind, namespace, name string,
+	get func() error) (bool, error) {
+	key := kind + "/" + namespace + "/" + name
+	if found, cached := exists[key]; cached {
+		return found, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	err := get()
+	if err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get %s %s/%s: %v", kind, namespace, name, err)
+	}
+	exists[key] = err == nil
+	return err == nil, nil
+}
+
+// podDependencies returns the names of ConfigMaps and Secrets referenced by the pod.
+func podDependencies(pod *apiv1.Pod) (configMaps, secrets []string) {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.ConfigMap != nil {
+			configMaps = append(configMaps, volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			secrets = append(secrets, volume.Secret.SecretName)
+		}
+	}
+	containers := append(append([]apiv1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				configMaps = append(configMaps, env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				secrets = append(secrets, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return configMaps, secrets
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPodsWithMissingDependencies(t *testing.T) {
+	configMap := &apiv1.ConfigMap{ObjectMeta: apiv1.ObjectMeta{Name: "config", Namespace: "default"}}
+	secret := &apiv1.Secret{ObjectMeta: apiv1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	fakeClient := fake.NewSimpleClientset(configMap, secret)
+
+	buildPod := func(name, configMapName, secretName string) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: apiv1.PodSpec{
+				Volumes: []apiv1.Volume{{
+					Name: "config",
+					VolumeSource: apiv1.VolumeSource{ConfigMap: &apiv1.ConfigMapVolumeSource{
+						LocalObjectReference: apiv1.LocalObjectReference{Name: configMapName},
+					}},
+				}},
+				Containers: []apiv1.Container{{
+					Name: "c",
+					Env: []apiv1.EnvVar{{
+						Name: "PASSWORD",
+						ValueFrom: &apiv1.EnvVarSource{SecretKeyRef: &apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+							Key:                  "password",
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	ok := buildPod("ok", "config", "secret")
+	noConfig := buildPod("noconfig", "gone", "secret")
+	noSecret := buildPod("nosecret", "config", "gone")
+
+	pods, err := GetPodsWithMissingDependencies(context.Background(), fakeClient, []*apiv1.Pod{ok, noConfig, noSecret})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{noConfig, noSecret}, pods)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	api "k8s.io/kubernetes/pkg/api"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// GetPodsForDeploymentDrain returns the pods of the given deployment that should be deleted to move the
+// deployment away, checked with the same rules as GetPodsForDeletionOnNodeDrainWithOptions. Pods
+// not matching the deployment selector are never returned.
+func GetPodsForDeploymentDrain(ctx context.Context, client client.Interface, deploymentName, namespace string,
+	opts DrainOptions) ([]*apiv1.Pod, error) {
+
+	deployment, err := client.Extensions().Deployments(namespace).Get(deploymentName)
+	if err != nil {
+		return []*apiv1.Pod{}, fmt.Errorf("failed to get deployment %s/%s: %v", namespace, deploymentName, err)
+	}
+	if deployment.Spec.Selector == nil {
+		return []*apiv1.Pod{}, fmt.Errorf("deployment %s/%s has no selector", namespace, deploymentName)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return []*apiv1.Pod{}, fmt.Errorf("invalid selector of deployment %s/%s: %v", namespace, deploymentName, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return []*apiv1.Pod{}, err
+	}
+	podList, err := client.Core().Pods(namespace).List(apiv1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return []*apiv1.Pod{}, fmt.Errorf("failed to list pods of deployment %s/%s: %v", namespace, deploymentName, err)
+	}
+	pods := make([]*apiv1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	return GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), client, opts)
+}
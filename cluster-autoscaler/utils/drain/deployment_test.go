@@ -0,0 +1,54 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPodsForDeploymentDrain(t *testing.T) {
+	webLabels := map[string]string{"app": "web"}
+	deployment := &extensions.Deployment{
+		ObjectMeta: apiv1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: extensions.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: webLabels},
+		},
+	}
+	web1 := buildReplicatedPod("web1", webLabels)
+	web2 := buildReplicatedPod("web2", webLabels)
+	other := buildReplicatedPod("other", map[string]string{"app": "other"})
+	fakeClient := fake.NewSimpleClientset(deployment, web1, web2, other)
+
+	pods, err := GetPodsForDeploymentDrain(context.Background(), fakeClient, "web", "default", DrainOptions{})
+	assert.NoError(t, err)
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	assert.Equal(t, []string{"web1", "web2"}, names)
+
+	_, err = GetPodsForDeploymentDrain(context.Background(), fakeClient, "missing", "default", DrainOptions{})
+	assert.Error(t, err)
+}
@@ -18,14 +18,145 @@ package drain
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
+	"time"
 
 	api "k8s.io/kubernetes/pkg/api"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
 	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/kubelet/types"
 	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/golang/glog"
 )
 
+// DrainOptions configures which pods are accepted for deletion on node drain.
+type DrainOptions struct {
+	// DeleteAll forces deletion of all pods, disabling the checks below.
+	DeleteAll bool
+	// SkipNodesWithSystemPods rejects the drain if a non-daemon-set, non-mirrored kube-system pod is present.
+	SkipNodesWithSystemPods bool
+	// SkipNodesWithLocalStorage rejects the drain if a pod with local storage is present.
+	SkipNodesWithLocalStorage bool
+	// SkipHostPIDPods rejects the drain if a pod sharing the host PID namespace is present.
+	SkipHostPIDPods bool
+	// SkipHostIPCPods rejects the drain if a pod sharing the host IPC namespace is present.
+	SkipHostIPCPods bool
+	// SkipRecentlyRestartedPods rejects the drain if a pod restarted within RecentRestartWindow.
+	SkipRecentlyRestartedPods bool
+	// RecentRestartWindow is the period in which a restart counts as recent, see SkipRecentlyRestartedPods.
+	RecentRestartWindow time.Duration
+	// SkipNeverPullPods rejects the drain if a pod with imagePullPolicy Never is present.
+	SkipNeverPullPods bool
+	// SkipGPUPods rejects the drain if a pod requesting GPUs is present. Otherwise such pods are only logged.
+	SkipGPUPods bool
+	// ForceEvictDevicePluginPods allows deleting pods that use node-local device plugin resources. Otherwise
+	// such pods reject the drain with ErrNodeLocalDevice, see GetDevicePluginPods.
+	ForceEvictDevicePluginPods bool
+	// ForceEvictNetworkCritical allows deleting pods with NetworkCriticalAnnotation. Otherwise such pods
+	// reject the drain.
+	ForceEvictNetworkCritical bool
+	// WarnAggressiveReadiness logs a warning for pods whose readiness probe fails after a single failure.
+	WarnAggressiveReadiness bool
+	// FailOnManuallyScheduledPods rejects the drain if a pod with ScheduleByAnnotation set to "manual" is
+	// present. Otherwise such pods are only logged.
+	FailOnManuallyScheduledPods bool
+	// WarnPinnedPods logs a warning for pods placed on the node without the scheduler, see GetPinnedPods.
+	WarnPinnedPods bool
+	// WarnHostIPPods logs a warning for pods that get the node IP through the Downward API, see
+	// GetDownwardAPIHostIPPods.
+	WarnHostIPPods bool
+	// LongLivedConnectionDrainDelay delays the eviction of pods with long-lived connections, see
+	// GetEvictionDelay. 0 means DefaultLongLivedConnectionDrainDelay.
+	LongLivedConnectionDrainDelay time.Duration
+	// WarnPrivateRegistryImages logs a warning for pods with images matching PrivateRegistryPatterns, see
+	// GetPrivateRegistryPods.
+	WarnPrivateRegistryImages bool
+	// PrivateRegistryPatterns are regular expressions matching images from registries only reachable from
+	// within the cluster.
+	PrivateRegistryPatterns []string
+	// CustomOwnerKinds maps owner kinds unknown to the drain logic (e.g. of CRD-based operators) to whether
+	// their pods can be evicted. Use RegisterCustomOwnerKind to populate it.
+	CustomOwnerKinds map[string]bool
+	// CheckReferences verifies that controllers of the pods still exist. Setting this to true
+	// requires client to be not-null.
+	CheckReferences bool
+	// MaxSimultaneousDrains limits the number of nodes drained in parallel, see RecommendedDrainParallelism.
+	// 0 means no explicit limit.
+	MaxSimultaneousDrains int
+	// MinReplica is the minimum number of replicas a replication controller or replica set
+	// should have to allow deletion of its pods.
+	MinReplica int32
+	// CrossRegionPolicy, if set and not allowing cross-region moves, rejects the drain if a pod could be
+	// rescheduled to a node outside of the drained node's region.
+	CrossRegionPolicy *CrossRegionDrainPolicy
+	// QuorumPolicies are consulted about the pods to be deleted, see RegisterQuorumPolicy.
+	QuorumPolicies map[string]QuorumSafeEvictionPolicy
+	// Metrics, if set, collects statistics of the drain. It is not compared by DrainOptionsEqual.
+	Metrics *DrainMetrics
+}
+
+// RegisterCustomOwnerKind teaches the drain logic about pods owned by kind. Pods of an evictable kind are
+// treated as replicated, pods of a non-evictable kind block the drain unless DeleteAll is set.
+func (o *DrainOptions) RegisterCustomOwnerKind(kind string, evictable bool) {
+	if o.CustomOwnerKinds == nil {
+		o.CustomOwnerKinds = make(map[string]bool)
+	}
+	o.CustomOwnerKinds[kind] = evictable
+}
+
+// DrainOptionsEqual checks whether a and b configure the drain in the same way. A nil and an empty
+// CustomOwnerKinds or QuorumPolicies are equal. QuorumPolicies are compared by identity, i.e. the same
+// names have to be registered with the same policy values, or the same pointers, funcs or maps.
+func DrainOptionsEqual(a, b DrainOptions) bool {
+	if !quorumPoliciesEqual(a.QuorumPolicies, b.QuorumPolicies) {
+		return false
+	}
+	for _, o := range []*DrainOptions{&a, &b} {
+		o.Metrics = nil
+		o.QuorumPolicies = nil
+		if len(o.CustomOwnerKinds) == 0 {
+			o.CustomOwnerKinds = nil
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func quorumPoliciesEqual(a, b map[string]QuorumSafeEvictionPolicy) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, policy := range a {
+		other, found := b[name]
+		if !found || !samePolicy(policy, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePolicy compares a and b with == if their type allows it. Funcs, maps and slices, which can't be
+// compared with ==, are the same if they point to the same code or data.
+func samePolicy(a, b QuorumSafeEvictionPolicy) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if reflect.TypeOf(a).Comparable() {
+		return a == b
+	}
+	switch va, vb := reflect.ValueOf(a), reflect.ValueOf(b); va.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return va.Pointer() == vb.Pointer()
+	default:
+		return false
+	}
+}
+
 // GetPodsForDeletionOnNodeDrain returns pods that should be deleted on node drain as well as some extra information
 // about possibly problematic pods (unreplicated and deamon sets).
 func GetPodsForDeletionOnNodeDrain(
@@ -38,7 +169,28 @@ func GetPodsForDeletionOnNodeDrain(
 	client client.Interface,
 	minReplica int32) ([]*apiv1.Pod, error) {
 
+	return GetPodsForDeletionOnNodeDrainWithOptions(podList, decoder, client, DrainOptions{
+		DeleteAll:                 deleteAll,
+		SkipNodesWithSystemPods:   skipNodesWithSystemPods,
+		SkipNodesWithLocalStorage: skipNodesWithLocalStorage,
+		CheckReferences:           checkReferences,
+		MinReplica:                minReplica,
+	})
+}
+
+// GetPodsForDeletionOnNodeDrainWithOptions works like GetPodsForDeletionOnNodeDrain with the checks
+// configured by opts.
+func GetPodsForDeletionOnNodeDrainWithOptions(
+	podList []*apiv1.Pod,
+	decoder runtime.Decoder,
+	client client.Interface,
+	opts DrainOptions) ([]*apiv1.Pod, error) {
+
 	pods := []*apiv1.Pod{}
+	var registryPatterns []*regexp.Regexp
+	if opts.WarnPrivateRegistryImages {
+		registryPatterns = compileRegistryPatterns(opts.PrivateRegistryPatterns)
+	}
 
 	for _, pod := range podList {
 		if IsMirrorPod(pod) {
@@ -58,15 +210,15 @@ func GetPodsForDeletionOnNodeDrain(
 		}
 
 		if refKind == "ReplicationController" {
-			if checkReferences {
+			if opts.CheckReferences {
 				rc, err := client.Core().ReplicationControllers(sr.Reference.Namespace).Get(sr.Reference.Name)
 				// Assume a reason for an error is because the RC is either
 				// gone/missing or that the rc has too few replicas configured.
 				// TODO: replace the minReplica check with pod disruption budget.
 				if err == nil && rc != nil {
-					if rc.Spec.Replicas != nil && *rc.Spec.Replicas < minReplica {
+					if rc.Spec.Replicas != nil && *rc.Spec.Replicas < opts.MinReplica {
 						return []*apiv1.Pod{}, fmt.Errorf("replication controller for %s/%s has too few replicas spec: %d min: %d",
-							pod.Namespace, pod.Name, rc.Spec.Replicas, minReplica)
+							pod.Namespace, pod.Name, rc.Spec.Replicas, opts.MinReplica)
 					}
 					replicated = true
 
@@ -77,7 +229,7 @@ func GetPodsForDeletionOnNodeDrain(
 				replicated = true
 			}
 		} else if refKind == "DaemonSet" {
-			if checkReferences {
+			if opts.CheckReferences {
 				ds, err := client.Extensions().DaemonSets(sr.Reference.Namespace).Get(sr.Reference.Name)
 
 				// Assume the only reason for an error is because the DaemonSet is
@@ -96,7 +248,7 @@ func GetPodsForDeletionOnNodeDrain(
 				daemonsetPod = true
 			}
 		} else if refKind == "Job" {
-			if checkReferences {
+			if opts.CheckReferences {
 				job, err := client.Batch().Jobs(sr.Reference.Namespace).Get(sr.Reference.Name)
 
 				// Assume the only reason for an error is because the Job is
@@ -111,16 +263,16 @@ func GetPodsForDeletionOnNodeDrain(
 				replicated = true
 			}
 		} else if refKind == "ReplicaSet" {
-			if checkReferences {
+			if opts.CheckReferences {
 				rs, err := client.Extensions().ReplicaSets(sr.Reference.Namespace).Get(sr.Reference.Name)
 
 				// Assume the only reason for an error is because the RS is
 				// gone/missing, not for any other cause.  TODO(mml): something more
 				// sophisticated than this
 				if err == nil && rs != nil {
-					if rs.Spec.Replicas != nil && *rs.Spec.Replicas < minReplica {
+					if rs.Spec.Replicas != nil && *rs.Spec.Replicas < opts.MinReplica {
 						return []*apiv1.Pod{}, fmt.Errorf("replication controller for %s/%s has too few replicas spec: %d min: %d",
-							pod.Namespace, pod.Name, rs.Spec.Replicas, minReplica)
+							pod.Namespace, pod.Name, rs.Spec.Replicas, opts.MinReplica)
 					}
 					replicated = true
 				} else {
@@ -129,23 +281,126 @@ func GetPodsForDeletionOnNodeDrain(
 			} else {
 				replicated = true
 			}
+		} else if evictable, found := opts.CustomOwnerKinds[refKind]; found {
+			if !evictable && !opts.DeleteAll {
+				return []*apiv1.Pod{}, fmt.Errorf("%s/%s is owned by %s which doesn't allow eviction", pod.Namespace, pod.Name, refKind)
+			}
+			replicated = true
 		}
 		if daemonsetPod {
 			continue
 		}
-		if !deleteAll {
+		if !opts.DeleteAll {
 			if !replicated {
-				return []*apiv1.Pod{}, fmt.Errorf("%s/%s is not replicated", pod.Namespace, pod.Name)
+				return []*apiv1.Pod{}, &NakedPodError{Namespace: pod.Namespace, Name: pod.Name}
 			}
-			if pod.Namespace == "kube-system" && skipNodesWithSystemPods {
+			// Only jobs recreate pods that are never restarted.
+			if pod.Spec.RestartPolicy == apiv1.RestartPolicyNever && refKind != "Job" {
+				return []*apiv1.Pod{}, fmt.Errorf("%s/%s has restart policy Never and won't be recreated", pod.Namespace, pod.Name)
+			}
+			if pod.Namespace == "kube-system" && opts.SkipNodesWithSystemPods {
 				return []*apiv1.Pod{}, fmt.Errorf("non-deamons set, non-mirrored, kube-system pod present: %s", pod.Name)
 			}
-			if HasLocalStorage(pod) && skipNodesWithLocalStorage {
+			if HasLocalStorage(pod) && opts.SkipNodesWithLocalStorage {
 				return []*apiv1.Pod{}, fmt.Errorf("pod with local storage present: %s", pod.Name)
 			}
+			if pod.Spec.HostPID && opts.SkipHostPIDPods {
+				return []*apiv1.Pod{}, fmt.Errorf("pod with host PID namespace present: %s", pod.Name)
+			}
+			if pod.Spec.HostIPC && opts.SkipHostIPCPods {
+				return []*apiv1.Pod{}, fmt.Errorf("pod with host IPC namespace present: %s", pod.Name)
+			}
+			if opts.SkipNeverPullPods && usesNeverPullPolicy(pod) {
+				return []*apiv1.Pod{}, fmt.Errorf("pod with never-pull image policy present: %s", pod.Name)
+			}
+			if isManuallyScheduled(pod) {
+				if opts.FailOnManuallyScheduledPods {
+					return []*apiv1.Pod{}, fmt.Errorf("manually scheduled pod present: %s", pod.Name)
+				}
+				glog.V(2).Infof("Pod %s/%s was scheduled manually and may not fit elsewhere", pod.Namespace, pod.Name)
+			}
+			if requestsGPU(pod) {
+				if opts.SkipGPUPods {
+					return []*apiv1.Pod{}, fmt.Errorf("pod requesting GPUs present: %s", pod.Name)
+				}
+				glog.V(2).Infof("Pod %s/%s requests GPUs and may take long to reschedule", pod.Namespace, pod.Name)
+			}
+			if !opts.ForceEvictNetworkCritical && isNetworkCritical(pod) {
+				return []*apiv1.Pod{}, fmt.Errorf("network-critical pod present: %s", pod.Name)
+			}
+			if opts.CrossRegionPolicy != nil && !opts.CrossRegionPolicy.AllowCrossRegion {
+				mayLeave, err := mayLeaveRegion(pod, opts.CrossRegionPolicy)
+				if err != nil {
+					return []*apiv1.Pod{}, err
+				}
+				if mayLeave {
+					glog.V(1).Infof("Pod %s/%s may be rescheduled to another region", pod.Namespace, pod.Name)
+					return []*apiv1.Pod{}, ErrCrossRegionNotAllowed
+				}
+			}
+			if !opts.ForceEvictDevicePluginPods && requestsDevice(pod) {
+				glog.V(1).Infof("Pod %s/%s uses node-local device resources", pod.Namespace, pod.Name)
+				return []*apiv1.Pod{}, ErrNodeLocalDevice
+			}
+			if opts.SkipRecentlyRestartedPods && restartedSince(pod, time.Now().Add(-opts.RecentRestartWindow)) {
+				return []*apiv1.Pod{}, fmt.Errorf("pod restarted recently: %s", pod.Name)
+			}
+			holdUntil, hasHold, err := GetEvictionHold(pod)
+			if err != nil {
+				glog.Warningf("Ignoring eviction hold: %v", err)
+			}
+			if hasHold && time.Now().Before(holdUntil) {
+				return []*apiv1.Pod{}, fmt.Errorf("pod with eviction hold until %s present: %s",
+					holdUntil.Format(time.RFC3339), pod.Name)
+			}
+		}
+		if opts.WarnAggressiveReadiness && hasAggressiveReadinessProbe(pod) {
+			glog.Warningf("Pod %s/%s has a readiness probe with failure threshold 1 and may flap after rescheduling",
+				pod.Namespace, pod.Name)
+		}
+		if opts.WarnPinnedPods && isPinned(pod) {
+			glog.Warningf("Pod %s/%s was placed on %s without the scheduler and may come back after eviction",
+				pod.Namespace, pod.Name, pod.Spec.NodeName)
+		}
+		if opts.WarnPrivateRegistryImages && usesPrivateRegistry(pod, registryPatterns) {
+			glog.Warningf("Pod %s/%s uses images from a private registry, pulling them elsewhere may be slow",
+				pod.Namespace, pod.Name)
+		}
+		if opts.WarnHostIPPods && usesDownwardAPIHostIP(pod) {
+			glog.Warningf("Pod %s/%s reads the node IP from status.hostIP, which will change after eviction",
+				pod.Namespace, pod.Name)
 		}
 		pods = append(pods, pod)
 	}
+	if !opts.DeleteAll {
+		if err := checkQuorumPolicies(pods, opts.QuorumPolicies); err != nil {
+			return []*apiv1.Pod{}, err
+		}
+	}
+	return pods, nil
+}
+
+// NakedPodError is returned when the drain is blocked by a pod that is not managed by any controller.
+type NakedPodError struct {
+	Namespace string
+	Name      string
+}
+
+func (e *NakedPodError) Error() string {
+	return fmt.Sprintf("%s/%s is not replicated", e.Namespace, e.Name)
+}
+
+// listPodsOnNode returns all pods scheduled on the node.
+func listPodsOnNode(client client.Interface, nodeName string) ([]*apiv1.Pod, error) {
+	podList, err := client.Core().Pods(apiv1.NamespaceAll).List(
+		apiv1.ListOptions{FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": nodeName}).String()})
+	if err != nil {
+		return []*apiv1.Pod{}, fmt.Errorf("failed to list pods on %s: %v", nodeName, err)
+	}
+	pods := make([]*apiv1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
 	return pods, nil
 }
 
@@ -180,9 +435,17 @@ func IsMirrorPod(pod *apiv1.Pod) bool {
 	return found
 }
 
-// HasLocalStorage returns true if pod has any local storage.
+// resourceEphemeralStorage is the resource under which newer kubelets account local scratch space.
+const resourceEphemeralStorage apiv1.ResourceName = "ephemeral-storage"
+
+// HasLocalStorage returns true if pod has any local storage. Disk-backed emptyDir volumes of pods with
+// an ephemeral storage limit are not counted, as that storage is reclaimable scratch space.
 func HasLocalStorage(pod *apiv1.Pod) bool {
+	scratchOnly := HasEphemeralStorageLimit(pod)
 	for _, volume := range pod.Spec.Volumes {
+		if scratchOnly && volume.EmptyDir != nil && volume.EmptyDir.Medium != apiv1.StorageMediumMemory {
+			continue
+		}
 		if isLocalVolume(&volume) {
 			return true
 		}
@@ -190,6 +453,39 @@ func HasLocalStorage(pod *apiv1.Pod) bool {
 	return false
 }
 
+// HasEphemeralStorageLimit checks whether all containers of the pod have an ephemeral storage limit.
+func HasEphemeralStorageLimit(pod *apiv1.Pod) bool {
+	if len(pod.Spec.Containers) == 0 {
+		return false
+	}
+	for _, container := range pod.Spec.Containers {
+		if _, found := container.Resources.Limits[resourceEphemeralStorage]; !found {
+			return false
+		}
+	}
+	return true
+}
+
 func isLocalVolume(volume *apiv1.Volume) bool {
 	return volume.HostPath != nil || volume.EmptyDir != nil
 }
+
+// IsReadOnlyPod checks whether all containers of the pod have a read-only root filesystem and the pod
+// uses no persistent volumes. Such pods keep no in-flight state and can be evicted at any time.
+func IsReadOnlyPod(pod *apiv1.Pod) bool {
+	if len(pod.Spec.Containers) == 0 {
+		return false
+	}
+	for _, container := range pod.Spec.Containers {
+		sc := container.SecurityContext
+		if sc == nil || sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+			return false
+		}
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -22,8 +22,10 @@ import (
 	"io"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	api "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/testapi"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
 	batchv1 "k8s.io/kubernetes/pkg/apis/batch/v1"
@@ -31,6 +33,8 @@ import (
 	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
 	"k8s.io/kubernetes/pkg/client/testing/core"
 	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDrain(t *testing.T) {
@@ -141,6 +145,48 @@ func TestDrain(t *testing.T) {
 		},
 	}
 
+	heldPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "default",
+			Annotations: map[string]string{
+				apiv1.CreatedByAnnotation:  refJSON(t, &rc),
+				SafeToEvictAfterAnnotation: time.Now().Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "node",
+		},
+	}
+
+	expiredHoldPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "default",
+			Annotations: map[string]string{
+				apiv1.CreatedByAnnotation:  refJSON(t, &rc),
+				SafeToEvictAfterAnnotation: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "node",
+		},
+	}
+
+	malformedHoldPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "default",
+			Annotations: map[string]string{
+				apiv1.CreatedByAnnotation:  refJSON(t, &rc),
+				SafeToEvictAfterAnnotation: "tomorrow",
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "node",
+		},
+	}
+
 	tests := []struct {
 		description string
 		pods        []*apiv1.Pod
@@ -188,6 +234,27 @@ func TestDrain(t *testing.T) {
 			expectFatal: true,
 			expectPods:  []*apiv1.Pod{},
 		},
+		{
+			description: "pod with eviction hold",
+			pods:        []*apiv1.Pod{heldPod},
+			rcs:         []apiv1.ReplicationController{rc},
+			expectFatal: true,
+			expectPods:  []*apiv1.Pod{},
+		},
+		{
+			description: "pod with expired eviction hold",
+			pods:        []*apiv1.Pod{expiredHoldPod},
+			rcs:         []apiv1.ReplicationController{rc},
+			expectFatal: false,
+			expectPods:  []*apiv1.Pod{expiredHoldPod},
+		},
+		{
+			description: "pod with malformed eviction hold",
+			pods:        []*apiv1.Pod{malformedHoldPod},
+			rcs:         []apiv1.ReplicationController{rc},
+			expectFatal: false,
+			expectPods:  []*apiv1.Pod{malformedHoldPod},
+		},
 	}
 
 	for _, test := range tests {
@@ -231,6 +298,187 @@ func TestDrain(t *testing.T) {
 	}
 }
 
+func TestDrainHostNamespacePods(t *testing.T) {
+	hostPIDSpec := apiv1.PodSpec{NodeName: "node", HostPID: true}
+	hostIPCSpec := apiv1.PodSpec{NodeName: "node", HostIPC: true}
+
+	dsPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "ds",
+			Namespace:   "default",
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: daemonSetCreatedBy},
+		},
+		Spec: hostPIDSpec,
+	}
+	nakedPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "naked", Namespace: "default"},
+		Spec:       hostPIDSpec,
+	}
+	rsPIDPod := buildReplicatedPod("rs-pid", nil)
+	rsPIDPod.Spec = hostPIDSpec
+	rsIPCPod := buildReplicatedPod("rs-ipc", nil)
+	rsIPCPod.Spec = hostIPCSpec
+
+	opts := DrainOptions{SkipHostPIDPods: true, SkipHostIPCPods: true}
+	decoder := api.Codecs.UniversalDecoder()
+
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{dsPod}, decoder, nil, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{nakedPod}, decoder, nil, opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not replicated")
+
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{rsPIDPod}, decoder, nil, opts)
+	assert.Error(t, err)
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{rsIPCPod}, decoder, nil, opts)
+	assert.Error(t, err)
+
+	pods, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{rsPIDPod, rsIPCPod}, decoder, nil, DrainOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{rsPIDPod, rsIPCPod}, pods)
+}
+
+func TestDrainRecentlyRestartedPods(t *testing.T) {
+	recent := buildRestartedPod("recent", 1, time.Now().Add(-time.Minute))
+	opts := DrainOptions{SkipRecentlyRestartedPods: true, RecentRestartWindow: 10 * time.Minute}
+	decoder := api.Codecs.UniversalDecoder()
+
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{recent}, decoder, nil, opts)
+	assert.Error(t, err)
+
+	opts.RecentRestartWindow = 30 * time.Second
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{recent}, decoder, nil, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{recent}, pods)
+}
+
+func TestDrainGPUPods(t *testing.T) {
+	cpuPod := buildReplicatedPod("cpu", nil)
+	gpuPod := buildGPUPod("gpu", "nvidia.com/gpu", 1)
+	decoder := api.Codecs.UniversalDecoder()
+
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{cpuPod, gpuPod}, decoder, nil, DrainOptions{SkipGPUPods: true})
+	assert.Error(t, err)
+
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{cpuPod, gpuPod}, decoder, nil, DrainOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{cpuPod, gpuPod}, pods)
+}
+
+func TestDrainCustomOwnerKinds(t *testing.T) {
+	buildOwnedPod := func(name, kind string) *apiv1.Pod {
+		pod := buildReplicatedPod(name, nil)
+		pod.Annotations[apiv1.CreatedByAnnotation] = fmt.Sprintf(
+			"{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"%s\"}}", kind)
+		return pod
+	}
+	etcdPod := buildOwnedPod("etcd", "EtcdCluster")
+	dbPod := buildOwnedPod("db", "PostgresCluster")
+	decoder := api.Codecs.UniversalDecoder()
+
+	opts := DrainOptions{}
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{etcdPod}, decoder, nil, opts)
+	assert.Error(t, err)
+
+	opts.RegisterCustomOwnerKind("EtcdCluster", true)
+	opts.RegisterCustomOwnerKind("PostgresCluster", false)
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{etcdPod}, decoder, nil, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{etcdPod}, pods)
+
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{etcdPod, dbPod}, decoder, nil, opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "PostgresCluster")
+}
+
+func TestDrainManuallyScheduledPods(t *testing.T) {
+	manualPod := buildReplicatedPod("manual", nil)
+	manualPod.Annotations[ScheduleByAnnotation] = "manual"
+	decoder := api.Codecs.UniversalDecoder()
+
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{manualPod}, decoder, nil,
+		DrainOptions{FailOnManuallyScheduledPods: true})
+	assert.Error(t, err)
+
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{manualPod}, decoder, nil, DrainOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{manualPod}, pods)
+}
+
+func TestDrainRestartPolicyNeverPods(t *testing.T) {
+	jobCreatedBy := "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"Job\"}}"
+	jobPod := buildReplicatedPod("job", nil)
+	jobPod.Annotations[apiv1.CreatedByAnnotation] = jobCreatedBy
+	jobPod.Spec.RestartPolicy = apiv1.RestartPolicyNever
+	customPod := buildReplicatedPod("custom", nil)
+	customPod.Annotations[apiv1.CreatedByAnnotation] = "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"TaskRunner\"}}"
+	customPod.Spec.RestartPolicy = apiv1.RestartPolicyNever
+	decoder := api.Codecs.UniversalDecoder()
+
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{jobPod}, decoder, nil, DrainOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{jobPod}, pods)
+
+	opts := DrainOptions{}
+	opts.RegisterCustomOwnerKind("TaskRunner", true)
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{customPod}, decoder, nil, opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "restart policy Never")
+}
+
+func TestDrainNetworkCriticalPods(t *testing.T) {
+	pod := buildReplicatedPod("proxy", nil)
+	pod.Annotations[NetworkCriticalAnnotation] = "true"
+
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{pod}, api.Codecs.UniversalDecoder(), nil, DrainOptions{})
+	assert.Error(t, err)
+
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{pod}, api.Codecs.UniversalDecoder(), nil,
+		DrainOptions{ForceEvictNetworkCritical: true})
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+}
+
+func TestHasLocalStorage(t *testing.T) {
+	buildPod := func(medium apiv1.StorageMedium, limits apiv1.ResourceList) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "default"},
+			Spec: apiv1.PodSpec{
+				Volumes: []apiv1.Volume{{
+					Name:         "scratch",
+					VolumeSource: apiv1.VolumeSource{EmptyDir: &apiv1.EmptyDirVolumeSource{Medium: medium}},
+				}},
+				Containers: []apiv1.Container{{Resources: apiv1.ResourceRequirements{Limits: limits}}},
+			},
+		}
+	}
+	limited := apiv1.ResourceList{resourceEphemeralStorage: resource.MustParse("1Gi")}
+
+	assert.True(t, HasLocalStorage(buildPod(apiv1.StorageMediumDefault, nil)))
+	assert.False(t, HasLocalStorage(buildPod(apiv1.StorageMediumDefault, limited)))
+	assert.True(t, HasLocalStorage(buildPod(apiv1.StorageMediumMemory, limited)))
+
+	assert.True(t, HasEphemeralStorageLimit(buildPod(apiv1.StorageMediumDefault, limited)))
+	assert.False(t, HasEphemeralStorageLimit(buildPod(apiv1.StorageMediumDefault, nil)))
+}
+
+func TestDrainOptionsEqual(t *testing.T) {
+	a := DrainOptions{SkipNodesWithSystemPods: true, MinReplica: 2}
+	b := DrainOptions{SkipNodesWithSystemPods: true, MinReplica: 2, CustomOwnerKinds: map[string]bool{}}
+	assert.True(t, DrainOptionsEqual(a, b))
+
+	b.RegisterCustomOwnerKind("EtcdCluster", true)
+	assert.False(t, DrainOptionsEqual(a, b))
+	a.RegisterCustomOwnerKind("EtcdCluster", true)
+	assert.True(t, DrainOptionsEqual(a, b))
+	a.RegisterCustomOwnerKind("EtcdCluster", false)
+	assert.False(t, DrainOptionsEqual(a, b))
+
+	assert.False(t, DrainOptionsEqual(DrainOptions{}, DrainOptions{RecentRestartWindow: time.Minute}))
+}
+
 func refJSON(t *testing.T, o runtime.Object) string {
 	ref, err := apiv1.GetReference(o)
 	if err != nil {
@@ -245,3 +493,28 @@ func refJSON(t *testing.T, o runtime.Object) string {
 func objBody(codec runtime.Codec, obj runtime.Object) io.ReadCloser {
 	return ioutil.NopCloser(bytes.NewReader([]byte(runtime.EncodeOrDie(codec, obj))))
 }
+
+func TestIsReadOnlyPod(t *testing.T) {
+	readOnly := true
+	writable := false
+	buildPod := func(rootFS ...*bool) *apiv1.Pod {
+		pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "default"}}
+		for _, fs := range rootFS {
+			pod.Spec.Containers = append(pod.Spec.Containers,
+				apiv1.Container{SecurityContext: &apiv1.SecurityContext{ReadOnlyRootFilesystem: fs}})
+		}
+		return pod
+	}
+
+	assert.True(t, IsReadOnlyPod(buildPod(&readOnly, &readOnly)))
+	assert.False(t, IsReadOnlyPod(buildPod(&readOnly, &writable)))
+	assert.False(t, IsReadOnlyPod(buildPod(&readOnly, nil)))
+	assert.False(t, IsReadOnlyPod(buildPod()))
+
+	withPVC := buildPod(&readOnly)
+	withPVC.Spec.Volumes = []apiv1.Volume{{
+		Name:         "data",
+		VolumeSource: apiv1.VolumeSource{PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+	}}
+	assert.False(t, IsReadOnlyPod(withPVC))
+}
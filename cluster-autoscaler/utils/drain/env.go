@@ -0,0 +1,48 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// hostIPFieldPath is the Downward API field holding the IP of the node the pod runs on.
+const hostIPFieldPath = "status.hostIP"
+
+// GetDownwardAPIHostIPPods returns pods with a container that gets the node IP injected through the
+// Downward API. The IP changes when the pod is rescheduled, which may break clients that cached it.
+func GetDownwardAPIHostIPPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if usesDownwardAPIHostIP(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func usesDownwardAPIHostIP(pod *apiv1.Pod) bool {
+	containers := append(append([]apiv1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.FieldPath == hostIPFieldPath {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDownwardAPIHostIPPods(t *testing.T) {
+	fieldEnv := func(name, path string) apiv1.EnvVar {
+		return apiv1.EnvVar{Name: name, ValueFrom: &apiv1.EnvVarSource{FieldRef: &apiv1.ObjectFieldSelector{FieldPath: path}}}
+	}
+	hostIP := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "hostip", Namespace: "default"},
+		Spec: apiv1.PodSpec{Containers: []apiv1.Container{
+			{Name: "c", Env: []apiv1.EnvVar{{Name: "FOO", Value: "bar"}, fieldEnv("NODE_IP", "status.hostIP")}},
+		}},
+	}
+	initHostIP := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "init", Namespace: "default"},
+		Spec: apiv1.PodSpec{
+			InitContainers: []apiv1.Container{{Name: "i", Env: []apiv1.EnvVar{fieldEnv("NODE_IP", "status.hostIP")}}},
+			Containers:     []apiv1.Container{{Name: "c"}},
+		},
+	}
+	podIP := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "podip", Namespace: "default"},
+		Spec: apiv1.PodSpec{Containers: []apiv1.Container{
+			{Name: "c", Env: []apiv1.EnvVar{fieldEnv("POD_IP", "status.podIP")}},
+		}},
+	}
+
+	result := GetDownwardAPIHostIPPods([]*apiv1.Pod{hostIP, initHostIP, podIP})
+	assert.Equal(t, []*apiv1.Pod{hostIP, initHostIP}, result)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/record"
+)
+
+const (
+	// DrainFailedReason is the reason of events about drain errors of no specific kind.
+	DrainFailedReason = "DrainFailed"
+	// PDBViolationReason is the reason of events about evictions refused because of a PodDisruptionBudget.
+	PDBViolationReason = "PDBViolation"
+	// NakedPodBlockedReason is the reason of events about drains blocked by a pod without a controller.
+	NakedPodBlockedReason = "NakedPodBlocked"
+)
+
+// RecordDrainError emits a Warning event about err on the node. The reason tells the kind of the error:
+// NakedPodBlockedReason for NakedPodError, PDBViolationReason for evictions refused because of a
+// PodDisruptionBudget (see IsPDBViolation) and DrainFailedReason otherwise, including API server
+// throttling. Nothing is recorded for a nil error.
+func RecordDrainError(recorder record.EventRecorder, node *apiv1.Node, err error) {
+	if err == nil {
+		return
+	}
+	reason := DrainFailedReason
+	if _, ok := err.(*NakedPodError); ok {
+		reason = NakedPodBlockedReason
+	} else if IsPDBViolation(err) {
+		reason = PDBViolationReason
+	}
+	recorder.Eventf(node, apiv1.EventTypeWarning, reason, "failed to drain the node: %v", err)
+}
+
+// IsPDBViolation checks whether err is an eviction refused because of a PodDisruptionBudget. The API
+// server reports both these refusals and request throttling as TooManyRequests, so they are told apart
+// by the status message.
+func IsPDBViolation(err error) bool {
+	if !errors.IsTooManyRequests(err) {
+		return false
+	}
+	status, ok := err.(errors.APIStatus)
+	return ok && strings.Contains(strings.ToLower(status.Status().Message), "disruption budget")
+}
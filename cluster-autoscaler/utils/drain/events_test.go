@@ -0,0 +1,75 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/record"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordDrainError(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+	recorder := record.NewFakeRecorder(10)
+
+	RecordDrainError(recorder, node, fmt.Errorf("boom"))
+	assert.Equal(t, "Warning DrainFailed failed to drain the node: boom", <-recorder.Events)
+
+	RecordDrainError(recorder, node, &NakedPodError{Namespace: "default", Name: "naked"})
+	assert.Equal(t, "Warning NakedPodBlocked failed to drain the node: default/naked is not replicated", <-recorder.Events)
+
+	pdbErr := &errors.StatusError{ErrStatus: metav1.Status{
+		Code:    errors.StatusTooManyRequests,
+		Message: "cannot evict pod as it would violate the pod's disruption budget",
+	}}
+	RecordDrainError(recorder, node, pdbErr)
+	assert.Equal(t, "Warning PDBViolation failed to drain the node: cannot evict pod as it would violate the pod's disruption budget",
+		<-recorder.Events)
+
+	throttled := &errors.StatusError{ErrStatus: metav1.Status{
+		Code:    errors.StatusTooManyRequests,
+		Message: "Too many requests, please try again later.",
+	}}
+	RecordDrainError(recorder, node, throttled)
+	assert.Equal(t, "Warning DrainFailed failed to drain the node: Too many requests, please try again later.",
+		<-recorder.Events)
+
+	RecordDrainError(recorder, node, nil)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestIsPDBViolation(t *testing.T) {
+	assert.True(t, IsPDBViolation(&errors.StatusError{ErrStatus: metav1.Status{
+		Code:    errors.StatusTooManyRequests,
+		Message: "Cannot evict pod as it would violate the pod's disruption budget.",
+	}}))
+	assert.False(t, IsPDBViolation(&errors.StatusError{ErrStatus: metav1.Status{
+		Code:    errors.StatusTooManyRequests,
+		Message: "Too many requests, please try again later.",
+	}}))
+	assert.False(t, IsPDBViolation(&errors.StatusError{ErrStatus: metav1.Status{
+		Code:    500,
+		Message: "disruption budget controller is down",
+	}}))
+	assert.False(t, IsPDBViolation(fmt.Errorf("disruption budget")))
+}
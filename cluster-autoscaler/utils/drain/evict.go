@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+
+	"github.com/golang/glog"
+)
+
+// defaultThrottleRetryAfter is used when a TooManyRequests response doesn't say when to retry.
+var defaultThrottleRetryAfter = time.Second
+
+// DrainMetrics collects statistics of a single drain operation.
+type DrainMetrics struct {
+	mutex          sync.Mutex
+	throttleEvents int
+}
+
+// ThrottleEvents returns the number of requests throttled by the API server.
+func (m *DrainMetrics) ThrottleEvents() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.throttleEvents
+}
+
+func (m *DrainMetrics) recordThrottle() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.throttleEvents++
+}
+
+// ThrottleAwareEvict evicts the pod, retrying as long as the API server throttles the request with
+// TooManyRequests. Before each retry it waits as long as the response asks (or a second if it doesn't
+// say), but gives up if that would pass the deadline of ctx. Throttle events are counted in
+// opts.Metrics, if set. Evictions refused because of a PodDisruptionBudget, which are reported with
+// TooManyRequests as well (see IsPDBViolation), are neither retried nor counted but returned right away.
+func ThrottleAwareEvict(ctx context.Context, client client.Interface, pod *apiv1.Pod, opts DrainOptions) error {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: apiv1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to evict %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		err := client.Core().Pods(pod.Namespace).Evict(eviction)
+		if err == nil || !errors.IsTooManyRequests(err) || IsPDBViolation(err) {
+			return err
+		}
+		opts.Metrics.recordThrottle()
+		retryAfter := throttleRetryAfter(err)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+			return fmt.Errorf("eviction of %s/%s throttled past its deadline: %v", pod.Namespace, pod.Name, err)
+		}
+		glog.V(2).Infof("Eviction of %s/%s throttled, retrying in %v", pod.Namespace, pod.Name, retryAfter)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to evict %s/%s: %v", pod.Namespace, pod.Name, ctx.Err())
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// throttleRetryAfter returns the delay requested by the Retry-After header of a TooManyRequests response.
+func throttleRetryAfter(err error) time.Duration {
+	if status, ok := err.(errors.APIStatus); ok {
+		if details := status.Status().Details; details != nil && details.RetryAfterSeconds > 0 {
+			return time.Duration(details.RetryAfterSeconds) * time.Second
+		}
+	}
+	return defaultThrottleRetryAfter
+}
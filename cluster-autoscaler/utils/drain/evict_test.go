@@ -0,0 +1,90 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+	"k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleAwareEvict(t *testing.T) {
+	defer func(d time.Duration) { defaultThrottleRetryAfter = d }(defaultThrottleRetryAfter)
+	defaultThrottleRetryAfter = time.Millisecond
+	throttled := &errors.StatusError{ErrStatus: metav1.Status{Code: errors.StatusTooManyRequests}}
+	throttledLong := &errors.StatusError{ErrStatus: metav1.Status{
+		Code:    errors.StatusTooManyRequests,
+		Details: &metav1.StatusDetails{RetryAfterSeconds: 10},
+	}}
+	pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	calls := 0
+	fakeClient := &fake.Clientset{}
+	fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		calls++
+		if calls < 2 {
+			return true, nil, throttled
+		}
+		return true, nil, nil
+	})
+	metrics := &DrainMetrics{}
+	err := ThrottleAwareEvict(context.Background(), fakeClient, pod, DrainOptions{Metrics: metrics})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, metrics.ThrottleEvents())
+
+	fakeClient = &fake.Clientset{}
+	fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, throttledLong
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = ThrottleAwareEvict(ctx, fakeClient, pod, DrainOptions{})
+	assert.Error(t, err)
+
+	fakeClient = &fake.Clientset{}
+	fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewNotFound(apiv1.Resource("pods"), "pod")
+	})
+	err = ThrottleAwareEvict(context.Background(), fakeClient, pod, DrainOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	pdbRefusal := &errors.StatusError{ErrStatus: metav1.Status{
+		Code:    errors.StatusTooManyRequests,
+		Message: "Cannot evict pod as it would violate the pod's disruption budget.",
+	}}
+	calls = 0
+	fakeClient = &fake.Clientset{}
+	fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		calls++
+		return true, nil, pdbRefusal
+	})
+	metrics = &DrainMetrics{}
+	err = ThrottleAwareEvict(context.Background(), fakeClient, pod, DrainOptions{Metrics: metrics})
+	assert.Equal(t, pdbRefusal, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, metrics.ThrottleEvents())
+}
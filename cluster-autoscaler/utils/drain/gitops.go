@@ -0,0 +1,80 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+const (
+	// ArgoCDAppNameAnnotation holds the ArgoCD Application managing the pod, either as "<name>" or as
+	// "<namespace>_<name>".
+	ArgoCDAppNameAnnotation = "argocd.argoproj.io/app-name"
+	// ArgoCDNamespace is where Applications given only by name are looked up.
+	ArgoCDNamespace = "argocd"
+)
+
+// argoCDApplication holds the parts of an ArgoCD Application status needed to check its sync status.
+type argoCDApplication struct {
+	Status struct {
+		Sync struct {
+			Status string `json:"status"`
+		} `json:"sync"`
+		Health struct {
+			Status string `json:"status"`
+		} `json:"health"`
+	} `json:"status"`
+}
+
+// CheckGitOpsSyncStatus checks whether the ArgoCD Application managing the pod is in sync and not
+// degraded. Otherwise the controller may be reconciling the pod right now and it shouldn't be evicted.
+// Pods not managed by ArgoCD and Applications that don't exist are reported as synced.
+func CheckGitOpsSyncStatus(ctx context.Context, client client.Interface, pod *apiv1.Pod) (bool, error) {
+	appName := pod.ObjectMeta.Annotations[ArgoCDAppNameAnnotation]
+	if appName == "" {
+		return true, nil
+	}
+	namespace := ArgoCDNamespace
+	if parts := strings.SplitN(appName, "_", 2); len(parts) == 2 {
+		namespace, appName = parts[0], parts[1]
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	body, err := client.Core().RESTClient().Get().
+		AbsPath("/apis/argoproj.io/v1alpha1/namespaces", namespace, "applications", appName).
+		DoRaw()
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get application %s/%s of %s/%s: %v",
+			namespace, appName, pod.Namespace, pod.Name, err)
+	}
+	var app argoCDApplication
+	if err := json.Unmarshal(body, &app); err != nil {
+		return false, fmt.Errorf("invalid application %s/%s: %v", namespace, appName, err)
+	}
+	return app.Status.Sync.Status != "OutOfSync" && app.Status.Health.Status != "Degraded", nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/client/restclient"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckGitOpsSyncStatus(t *testing.T) {
+	applications := map[string]string{
+		"/apis/argoproj.io/v1alpha1/namespaces/argocd/applications/synced":   `{"status":{"sync":{"status":"Synced"},"health":{"status":"Healthy"}}}`,
+		"/apis/argoproj.io/v1alpha1/namespaces/argocd/applications/outdated": `{"status":{"sync":{"status":"OutOfSync"},"health":{"status":"Healthy"}}}`,
+		"/apis/argoproj.io/v1alpha1/namespaces/team/applications/degraded":   `{"status":{"sync":{"status":"Synced"},"health":{"status":"Degraded"}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, found := applications[r.URL.Path]
+		if !found {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	kubeClient, err := client.NewForConfig(&restclient.Config{Host: server.URL})
+	assert.NoError(t, err)
+
+	for app, expected := range map[string]bool{
+		"":              true,
+		"synced":        true,
+		"outdated":      false,
+		"team_degraded": false,
+		"missing":       true,
+	} {
+		pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{
+			Name:        "pod",
+			Namespace:   "default",
+			Annotations: map[string]string{ArgoCDAppNameAnnotation: app},
+		}}
+		synced, err := CheckGitOpsSyncStatus(context.Background(), kubeClient, pod)
+		assert.NoError(t, err, app)
+		assert.Equal(t, expected, synced, app)
+	}
+}
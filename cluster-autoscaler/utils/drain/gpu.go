@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// ErrNodeLocalDevice is returned when the drain is blocked by a pod using a device plugin resource.
+var ErrNodeLocalDevice = fmt.Errorf("pod using node-local device resources present")
+
+// standardResources lists the resource names that are not bound to devices of a particular node.
+var standardResources = map[apiv1.ResourceName]bool{
+	apiv1.ResourceCPU:        true,
+	apiv1.ResourceMemory:     true,
+	apiv1.ResourceStorage:    true,
+	resourceEphemeralStorage: true,
+}
+
+// gpuResources lists the resource names under which GPUs are requested.
+var gpuResources = []apiv1.ResourceName{
+	apiv1.ResourceNvidiaGPU,
+	"nvidia.com/gpu",
+	"amd.com/gpu",
+}
+
+// GetGPUPods returns pods requesting GPUs. GPU nodes are scarce, so such pods may take long to reschedule.
+func GetGPUPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if requestsGPU(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func requestsGPU(pod *apiv1.Pod) bool {
+	for _, name := range gpuResources {
+		if requestsResource(pod, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDevicePluginPods returns pods requesting resources other than the standard ones and GPUs, e.g.
+// FPGAs or NIC offload exposed by device plugins. Such devices are bound to the node, so a pod may not
+// find them anywhere else.
+func GetDevicePluginPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if requestsDevice(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func requestsDevice(pod *apiv1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		for name, value := range container.Resources.Requests {
+			if standardResources[name] || isGPUResource(name) || value.IsZero() {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func isGPUResource(name apiv1.ResourceName) bool {
+	for _, gpu := range gpuResources {
+		if name == gpu {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	api "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildGPUPod(name string, resourceName apiv1.ResourceName, count int64) *apiv1.Pod {
+	pod := buildReplicatedPod(name, nil)
+	pod.Spec.Containers = []apiv1.Container{{
+		Resources: apiv1.ResourceRequirements{
+			Requests: apiv1.ResourceList{resourceName: *resource.NewQuantity(count, resource.DecimalSI)},
+		},
+	}}
+	return pod
+}
+
+func TestGetGPUPods(t *testing.T) {
+	nvidia := buildGPUPod("nvidia", "nvidia.com/gpu", 1)
+	amd := buildGPUPod("amd", "amd.com/gpu", 2)
+	alpha := buildGPUPod("alpha", apiv1.ResourceNvidiaGPU, 1)
+	zero := buildGPUPod("zero", "nvidia.com/gpu", 0)
+	cpu := buildGPUPod("cpu", apiv1.ResourceCPU, 1)
+
+	result := GetGPUPods([]*apiv1.Pod{nvidia, amd, alpha, zero, cpu})
+	assert.Equal(t, []*apiv1.Pod{nvidia, amd, alpha}, result)
+}
+
+func TestGetDevicePluginPods(t *testing.T) {
+	fpga := buildGPUPod("fpga", "intel.com/fpga", 1)
+	gpu := buildGPUPod("gpu", "nvidia.com/gpu", 1)
+	cpu := buildGPUPod("cpu", apiv1.ResourceCPU, 2)
+	zero := buildGPUPod("zero", "intel.com/fpga", 0)
+
+	assert.Equal(t, []*apiv1.Pod{fpga}, GetDevicePluginPods([]*apiv1.Pod{fpga, gpu, cpu, zero}))
+}
+
+func TestDrainDevicePluginPods(t *testing.T) {
+	pods := []*apiv1.Pod{buildGPUPod("fpga", "intel.com/fpga", 1)}
+
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), nil, DrainOptions{})
+	assert.Equal(t, ErrNodeLocalDevice, err)
+
+	result, err := GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), nil,
+		DrainOptions{ForceEvictDevicePluginPods: true})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+}
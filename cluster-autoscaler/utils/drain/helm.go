@@ -0,0 +1,44 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+const (
+	// HelmReleaseNameAnnotation holds the name of the Helm release that deployed the object.
+	HelmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	// HelmReleaseNamespaceAnnotation holds the namespace of the Helm release that deployed the object.
+	HelmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// GetHelmReleasePods groups pods deployed by Helm by their release, keyed by "<release name>/<release
+// namespace>", so that the impact of a drain can be reported per release. Pods without the release
+// name annotation are not returned.
+func GetHelmReleasePods(pods []*apiv1.Pod) map[string][]*apiv1.Pod {
+	result := map[string][]*apiv1.Pod{}
+	for _, pod := range pods {
+		name := pod.ObjectMeta.Annotations[HelmReleaseNameAnnotation]
+		if name == "" {
+			continue
+		}
+		key := name + "/" + pod.ObjectMeta.Annotations[HelmReleaseNamespaceAnnotation]
+		result[key] = append(result[key], pod)
+	}
+	return result
+}
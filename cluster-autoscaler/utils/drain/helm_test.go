@@ -0,0 +1,46 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHelmReleasePods(t *testing.T) {
+	buildPod := func(name, release, namespace string) *apiv1.Pod {
+		pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default", Annotations: map[string]string{}}}
+		if release != "" {
+			pod.Annotations[HelmReleaseNameAnnotation] = release
+			pod.Annotations[HelmReleaseNamespaceAnnotation] = namespace
+		}
+		return pod
+	}
+	web1 := buildPod("web-1", "web", "prod")
+	web2 := buildPod("web-2", "web", "prod")
+	webStaging := buildPod("web-3", "web", "staging")
+	plain := buildPod("plain", "", "")
+
+	result := GetHelmReleasePods([]*apiv1.Pod{web1, plain, web2, webStaging})
+	assert.Equal(t, map[string][]*apiv1.Pod{
+		"web/prod":    {web1, web2},
+		"web/staging": {webStaging},
+	}, result)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// GetHPAConstrainedPods returns pods whose scale target is controlled by a HorizontalPodAutoscaler
+// that currently runs at its minReplicas. Evicting such a pod drops the group below the minimum
+// even if there is no PodDisruptionBudget for it.
+func GetHPAConstrainedPods(ctx context.Context, client client.Interface, pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	return getPodsByHPA(ctx, client, pods, func(hpa *autoscaling.HorizontalPodAutoscaler) bool {
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		return hpa.Status.CurrentReplicas <= minReplicas
+	})
+}
+
+// GetPodsUnderHPAScale returns pods whose scale target is being scaled up by a HorizontalPodAutoscaler,
+// i.e. its desired replicas exceed the current ones. New replicas are already being created, so
+// evicting such pods is safe.
+func GetPodsUnderHPAScale(ctx context.Context, client client.Interface, pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	return getPodsByHPA(ctx, client, pods, func(hpa *autoscaling.HorizontalPodAutoscaler) bool {
+		return hpa.Status.DesiredReplicas > hpa.Status.CurrentReplicas
+	})
+}
+
+// getPodsByHPA returns pods whose scale target is controlled by a HorizontalPodAutoscaler for which
+// matches returns true. HPAs and deployments are listed once per namespace.
+func getPodsByHPA(ctx context.Context, client client.Interface, pods []*apiv1.Pod,
+	matches func(*autoscaling.HorizontalPodAutoscaler) bool) ([]*apiv1.Pod, error) {
+	hpasByNamespace := make(map[string][]autoscaling.HorizontalPodAutoscaler)
+	deploymentsByNamespace := make(map[string][]extensions.Deployment)
+
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if err := ctx.Err(); err != nil {
+			return []*apiv1.Pod{}, err
+		}
+		hpas, found := hpasByNamespace[pod.Namespace]
+		if !found {
+			hpaList, err := client.Autoscaling().HorizontalPodAutoscalers(pod.Namespace).List(apiv1.ListOptions{})
+			if err != nil {
+				return []*apiv1.Pod{}, fmt.Errorf("failed to list horizontal pod autoscalers in %s: %v", pod.Namespace, err)
+			}
+			hpas = hpaList.Items
+			hpasByNamespace[pod.Namespace] = hpas
+		}
+		if len(hpas) == 0 {
+			continue
+		}
+
+		targets, err := scaleTargetsForPod(client, pod, deploymentsByNamespace)
+		if err != nil {
+			return []*apiv1.Pod{}, err
+		}
+		for i := range hpas {
+			hpa := &hpas[i]
+			if targets[hpa.Spec.ScaleTargetRef.Kind+"/"+hpa.Spec.ScaleTargetRef.Name] && matches(hpa) {
+				result = append(result, pod)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// scaleTargetsForPod returns kind/name keys of the objects that may be an HPA scale target for the pod:
+// its creator and, for replica set pods, the deployment owning the replica set.
+func scaleTargetsForPod(client client.Interface, pod *apiv1.Pod,
+	deploymentsByNamespace map[string][]extensions.Deployment) (map[string]bool, error) {
+
+	targets := make(map[string]bool)
+	sr, err := CreatorRef(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain refkind for %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	if sr == nil {
+		return targets, nil
+	}
+	targets[sr.Reference.Kind+"/"+sr.Reference.Name] = true
+	if sr.Reference.Kind != "ReplicaSet" {
+		return targets, nil
+	}
+
+	deployments, found := deploymentsByNamespace[pod.Namespace]
+	if !found {
+		deploymentList, err := client.Extensions().Deployments(pod.Namespace).List(apiv1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments in %s: %v", pod.Namespace, err)
+		}
+		deployments = deploymentList.Items
+		deploymentsByNamespace[pod.Namespace] = deployments
+	}
+	deployment, err := deploymentForPod(deployments, pod)
+	if err != nil {
+		return nil, err
+	}
+	if deployment != nil {
+		targets["Deployment/"+deployment.Name] = true
+	}
+	return targets, nil
+}
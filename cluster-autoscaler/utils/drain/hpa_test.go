@@ -0,0 +1,133 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/testapi"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHPAConstrainedPods(t *testing.T) {
+	minReplicas := int32(2)
+
+	rs := extensions.ReplicaSet{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "web-1234",
+			Namespace: "default",
+			SelfLink:  testapi.Default.SelfLink("replicasets", "web-1234"),
+		},
+	}
+	rc := apiv1.ReplicationController{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "api",
+			Namespace: "default",
+			SelfLink:  testapi.Default.SelfLink("replicationcontrollers", "api"),
+		},
+	}
+	deployment := &extensions.Deployment{
+		ObjectMeta: apiv1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: extensions.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+	webHPA := &autoscaling.HorizontalPodAutoscaler{
+		ObjectMeta: apiv1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			MinReplicas:    &minReplicas,
+		},
+		Status: autoscaling.HorizontalPodAutoscalerStatus{CurrentReplicas: 2},
+	}
+	apiHPA := &autoscaling.HorizontalPodAutoscaler{
+		ObjectMeta: apiv1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{Kind: "ReplicationController", Name: "api"},
+			MinReplicas:    &minReplicas,
+		},
+		Status: autoscaling.HorizontalPodAutoscalerStatus{CurrentReplicas: 5},
+	}
+
+	webPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "web-1234-abcd",
+			Namespace:   "default",
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: refJSON(t, &rs)},
+		},
+	}
+	apiPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "api-abcd",
+			Namespace:   "default",
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: refJSON(t, &rc)},
+		},
+	}
+	nakedPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "naked", Namespace: "default"},
+	}
+
+	fakeClient := fake.NewSimpleClientset(deployment, webHPA, apiHPA)
+	pods, err := GetHPAConstrainedPods(context.Background(), fakeClient, []*apiv1.Pod{webPod, apiPod, nakedPod})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{webPod}, pods)
+}
+
+func TestGetPodsUnderHPAScale(t *testing.T) {
+	rc := apiv1.ReplicationController{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "api",
+			Namespace: "default",
+			SelfLink:  testapi.Default.SelfLink("replicationcontrollers", "api"),
+		},
+	}
+	buildHPA := func(currentReplicas, desiredReplicas int32) *autoscaling.HorizontalPodAutoscaler {
+		return &autoscaling.HorizontalPodAutoscaler{
+			ObjectMeta: apiv1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: autoscaling.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscaling.CrossVersionObjectReference{Kind: "ReplicationController", Name: "api"},
+			},
+			Status: autoscaling.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: currentReplicas,
+				DesiredReplicas: desiredReplicas,
+			},
+		}
+	}
+	apiPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "api-abcd",
+			Namespace:   "default",
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: refJSON(t, &rc)},
+		},
+	}
+
+	pods, err := GetPodsUnderHPAScale(context.Background(), fake.NewSimpleClientset(buildHPA(2, 4)), []*apiv1.Pod{apiPod})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{apiPod}, pods)
+
+	pods, err = GetPodsUnderHPAScale(context.Background(), fake.NewSimpleClientset(buildHPA(4, 4)), []*apiv1.Pod{apiPod})
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"regexp"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/golang/glog"
+)
+
+// GetPrivateRegistryPods returns pods with a container image matching one of privateRegistryPatterns
+// (regular expressions, e.g. `^registry\.internal/`). Pulling such images on another node may be slow or
+// fail. Invalid patterns are logged and ignored.
+func GetPrivateRegistryPods(pods []*apiv1.Pod, privateRegistryPatterns []string) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	patterns := compileRegistryPatterns(privateRegistryPatterns)
+	for _, pod := range pods {
+		if usesPrivateRegistry(pod, patterns) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func compileRegistryPatterns(privateRegistryPatterns []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(privateRegistryPatterns))
+	for _, pattern := range privateRegistryPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			glog.Warningf("Ignoring invalid private registry pattern %q: %v", pattern, err)
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns
+}
+
+func usesPrivateRegistry(pod *apiv1.Pod, patterns []*regexp.Regexp) bool {
+	containers := append(append([]apiv1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, re := range patterns {
+			if re.MatchString(container.Image) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetNeverPullImagePods returns pods with a container using imagePullPolicy Never. They rely on the image
+// being present on the node and may not start on another one.
+func GetNeverPullImagePods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if usesNeverPullPolicy(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func usesNeverPullPolicy(pod *apiv1.Pod) bool {
+	containers := append(append([]apiv1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		if container.ImagePullPolicy == apiv1.PullNever {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	api "k8s.io/kubernetes/pkg/api"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPrivateRegistryPods(t *testing.T) {
+	buildPod := func(name string, images ...string) *apiv1.Pod {
+		pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"}}
+		for _, image := range images {
+			pod.Spec.Containers = append(pod.Spec.Containers, apiv1.Container{Image: image})
+		}
+		return pod
+	}
+	private := buildPod("private", "gcr.io/google_containers/pause:3.0", "registry.internal:5000/app:1.2")
+	public := buildPod("public", "nginx:1.11", "gcr.io/google_containers/pause:3.0")
+
+	result := GetPrivateRegistryPods([]*apiv1.Pod{private, public}, []string{`^registry\.internal(:\d+)?/`, "[invalid"})
+	assert.Equal(t, []*apiv1.Pod{private}, result)
+	assert.Empty(t, GetPrivateRegistryPods([]*apiv1.Pod{private, public}, nil))
+}
+
+func TestGetNeverPullImagePods(t *testing.T) {
+	never := buildReplicatedPod("never", nil)
+	never.Spec.Containers = []apiv1.Container{{ImagePullPolicy: apiv1.PullIfNotPresent}, {ImagePullPolicy: apiv1.PullNever}}
+	always := buildReplicatedPod("always", nil)
+	always.Spec.Containers = []apiv1.Container{{ImagePullPolicy: apiv1.PullAlways}}
+
+	assert.Equal(t, []*apiv1.Pod{never}, GetNeverPullImagePods([]*apiv1.Pod{never, always}))
+
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{never}, api.Codecs.UniversalDecoder(), nil,
+		DrainOptions{SkipNeverPullPods: true})
+	assert.Error(t, err)
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{never}, api.Codecs.UniversalDecoder(), nil,
+		DrainOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"regexp"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// MigrationInitContainerPattern matches names of init containers that run migrations, which may take
+// minutes to complete after the pod is rescheduled.
+var MigrationInitContainerPattern = regexp.MustCompile(`^.*-(migration|init-db)$`)
+
+// GetMigrationInitContainerPods returns pods with an init container matching MigrationInitContainerPattern.
+func GetMigrationInitContainerPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.InitContainers {
+			if MigrationInitContainerPattern.MatchString(container.Name) {
+				result = append(result, pod)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// EstimateInitContainerRuntime returns how long the init containers of the pod ran the last time, based
+// on their terminated states. The pod will likely need that much time again after being rescheduled.
+func EstimateInitContainerRuntime(pod *apiv1.Pod) time.Duration {
+	var total time.Duration
+	for _, status := range pod.Status.InitContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil {
+			total += terminated.FinishedAt.Sub(terminated.StartedAt.Time)
+		}
+	}
+	return total
+}
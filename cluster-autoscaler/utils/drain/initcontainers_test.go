@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildPodWithInitContainers(name string, initContainers ...string) *apiv1.Pod {
+	pod := buildReplicatedPod(name, nil)
+	for _, container := range initContainers {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, apiv1.Container{Name: container})
+	}
+	return pod
+}
+
+func TestGetMigrationInitContainerPods(t *testing.T) {
+	migration := buildPodWithInitContainers("migration", "wait-for-db", "schema-migration")
+	initDB := buildPodWithInitContainers("init-db", "app-init-db")
+	plain := buildPodWithInitContainers("plain", "migration-notes")
+
+	result := GetMigrationInitContainerPods([]*apiv1.Pod{migration, initDB, plain})
+	assert.Equal(t, []*apiv1.Pod{migration, initDB}, result)
+}
+
+func TestEstimateInitContainerRuntime(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	pod := buildPodWithInitContainers("migration", "wait-for-db", "schema-migration")
+	pod.Status.InitContainerStatuses = []apiv1.ContainerStatus{
+		{State: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{
+			StartedAt: metav1.NewTime(start), FinishedAt: metav1.NewTime(start.Add(10 * time.Second)),
+		}}},
+		{State: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{
+			StartedAt: metav1.NewTime(start.Add(10 * time.Second)), FinishedAt: metav1.NewTime(start.Add(3 * time.Minute)),
+		}}},
+	}
+	assert.Equal(t, 3*time.Minute, EstimateInitContainerRuntime(pod))
+	assert.Equal(t, time.Duration(0), EstimateInitContainerRuntime(buildReplicatedPod("plain", nil)))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// WaitForPodsToLeaveLoadBalancer polls the pods until the readinessGate condition of each of them becomes
+// False, which indicates that the pod was deregistered from its load balancer. Pods that are gone or
+// don't report the condition are not waited for. An error is returned if some pods are still registered
+// after lbDeregistrationTimeout, when ctx is done or if checkInterval is not positive.
+func WaitForPodsToLeaveLoadBalancer(ctx context.Context, client client.Interface, pods []*apiv1.Pod,
+	readinessGate string, checkInterval, lbDeregistrationTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, lbDeregistrationTimeout)
+	defer cancel()
+	return waitForPodCondition(ctx, client, pods, apiv1.PodConditionType(readinessGate), checkInterval)
+}
+
+// WaitForPodsToDeregisterFromLB polls the pods every lbHealthCheckInterval until none of them is Ready.
+// It is meant to be called after the pods' eviction or deletion has been issued: a pod stops being Ready
+// once the kubelet begins its graceful shutdown, after which load balancer health checks take it out of
+// rotation. Waiting for this before proceeding (e.g. with removing the node) ensures the pods are
+// deregistered before they become unreachable. Healthy pods that are not being shut down stay Ready, so
+// calling it before eviction only waits until ctx is done. Pods that are gone are not waited for. An
+// error is returned if ctx is done first or lbHealthCheckInterval is not positive.
+func WaitForPodsToDeregisterFromLB(ctx context.Context, client client.Interface, pods []*apiv1.Pod,
+	lbHealthCheckInterval time.Duration) error {
+	return waitForPodCondition(ctx, client, pods, apiv1.PodReady, lbHealthCheckInterval)
+}
+
+// waitForPodCondition polls the pods until conditionType is not True for any of them. Pods that are gone
+// or were replaced by a pod with the same name and another UID are not waited for.
+func waitForPodCondition(ctx context.Context, client client.Interface, pods []*apiv1.Pod,
+	conditionType apiv1.PodConditionType, checkInterval time.Duration) error {
+	if checkInterval <= 0 {
+		return fmt.Errorf("check interval must be positive, got %v", checkInterval)
+	}
+	remaining := pods
+	for {
+		stillTrue := []*apiv1.Pod{}
+		for _, pod := range remaining {
+			fresh, err := client.Core().Pods(pod.Namespace).Get(pod.Name)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			// A pod recreated under the same name (e.g. by a StatefulSet) is not the one waited for.
+			if fresh.UID != pod.UID {
+				continue
+			}
+			if podConditionIsTrue(fresh, conditionType) {
+				stillTrue = append(stillTrue, pod)
+			}
+		}
+		remaining = stillTrue
+		if len(remaining) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			names := make([]string, 0, len(remaining))
+			for _, pod := range remaining {
+				names = append(names, pod.Namespace+"/"+pod.Name)
+			}
+			return fmt.Errorf("condition %s still true for pods %s: %v", conditionType, strings.Join(names, ","), ctx.Err())
+		case <-time.After(checkInterval):
+		}
+	}
+}
+
+func podConditionIsTrue(pod *apiv1.Pod, conditionType apiv1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+	"k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testReadinessGate = "example.com/load-balancer-registered"
+
+func buildPodWithCondition(name string, conditionType apiv1.PodConditionType, status apiv1.ConditionStatus) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+		Status: apiv1.PodStatus{
+			Conditions: []apiv1.PodCondition{{Type: conditionType, Status: status}},
+		},
+	}
+}
+
+func TestWaitForPodsToLeaveLoadBalancer(t *testing.T) {
+	registered := buildPodWithCondition("registered", testReadinessGate, apiv1.ConditionTrue)
+	deregistered := buildPodWithCondition("deregistered", testReadinessGate, apiv1.ConditionFalse)
+
+	fakeClient := fake.NewSimpleClientset(deregistered)
+	err := WaitForPodsToLeaveLoadBalancer(context.Background(), fakeClient, []*apiv1.Pod{deregistered, registered},
+		testReadinessGate, time.Millisecond, time.Second)
+	assert.NoError(t, err)
+
+	fakeClient = fake.NewSimpleClientset(registered)
+	err = WaitForPodsToLeaveLoadBalancer(context.Background(), fakeClient, []*apiv1.Pod{registered},
+		testReadinessGate, time.Millisecond, 20*time.Millisecond)
+	assert.Error(t, err)
+
+	// The pod leaves the load balancer after a few checks.
+	checks := 0
+	fakeClient = &fake.Clientset{}
+	fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		checks++
+		if checks < 3 {
+			return true, registered, nil
+		}
+		return true, deregistered, nil
+	})
+	err = WaitForPodsToLeaveLoadBalancer(context.Background(), fakeClient, []*apiv1.Pod{registered},
+		testReadinessGate, time.Millisecond, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, checks)
+}
+
+func TestWaitForPodsToDeregisterFromLB(t *testing.T) {
+	ready := buildPodWithCondition("ready", apiv1.PodReady, apiv1.ConditionTrue)
+	notReady := buildPodWithCondition("not-ready", apiv1.PodReady, apiv1.ConditionFalse)
+
+	fakeClient := fake.NewSimpleClientset(notReady)
+	err := WaitForPodsToDeregisterFromLB(context.Background(), fakeClient, []*apiv1.Pod{notReady, ready},
+		time.Millisecond)
+	assert.NoError(t, err)
+
+	fakeClient = fake.NewSimpleClientset(ready)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = WaitForPodsToDeregisterFromLB(ctx, fakeClient, []*apiv1.Pod{ready}, time.Millisecond)
+	assert.Error(t, err)
+
+	// The eviction has been issued and the pod stops being Ready during its graceful shutdown.
+	checks := 0
+	fakeClient = &fake.Clientset{}
+	fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		checks++
+		if checks < 3 {
+			return true, ready, nil
+		}
+		return true, notReady, nil
+	})
+	err = WaitForPodsToDeregisterFromLB(context.Background(), fakeClient, []*apiv1.Pod{ready}, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, checks)
+
+	// A StatefulSet pod recreated elsewhere under the same name becomes Ready again.
+	evicted := buildPodWithCondition("web-0", apiv1.PodReady, apiv1.ConditionTrue)
+	evicted.UID = "old"
+	recreated := buildPodWithCondition("web-0", apiv1.PodReady, apiv1.ConditionTrue)
+	recreated.UID = "new"
+	err = WaitForPodsToDeregisterFromLB(context.Background(), fake.NewSimpleClientset(recreated),
+		[]*apiv1.Pod{evicted}, time.Millisecond)
+	assert.NoError(t, err)
+
+	checks = 0
+	err = WaitForPodsToDeregisterFromLB(context.Background(), fakeClient, []*apiv1.Pod{ready}, 0)
+	assert.Error(t, err)
+	assert.Equal(t, 0, checks)
+}
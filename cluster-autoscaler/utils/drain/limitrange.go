@@ -0,0 +1,110 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// GetLimitRangeViolatingPods returns pods whose resources fall outside of the Container or Pod minimums
+// and maximums of a LimitRange in their namespace. Such pods would be rejected by admission when they
+// are recreated after eviction, e.g. because the LimitRange was created after they started. Resources
+// left unset are not checked, as admission fills them with defaults.
+func GetLimitRangeViolatingPods(ctx context.Context, client client.Interface,
+	pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	limitRanges := map[string][]apiv1.LimitRange{}
+	for _, pod := range pods {
+		ranges, found := limitRanges[pod.Namespace]
+		if !found {
+			if err := ctx.Err(); err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			list, err := client.Core().LimitRanges(pod.Namespace).List(apiv1.ListOptions{})
+			if err != nil {
+				return []*apiv1.Pod{}, fmt.Errorf("failed to list limit ranges in %s: %v", pod.Namespace, err)
+			}
+			ranges = list.Items
+			limitRanges[pod.Namespace] = ranges
+		}
+		if violatesLimitRanges(pod, ranges) {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+func violatesLimitRanges(pod *apiv1.Pod, ranges []apiv1.LimitRange) bool {
+	podRequests := apiv1.ResourceList{}
+	podLimits := apiv1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(podRequests, container.Resources.Requests)
+		addResources(podLimits, container.Resources.Limits)
+	}
+	for _, limitRange := range ranges {
+		for _, item := range limitRange.Spec.Limits {
+			switch item.Type {
+			case apiv1.LimitTypeContainer:
+				for _, container := range pod.Spec.Containers {
+					if !withinLimitRangeItem(container.Resources.Requests, container.Resources.Limits, item) {
+						return true
+					}
+				}
+			case apiv1.LimitTypePod:
+				if !withinLimitRangeItem(podRequests, podLimits, item) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// withinLimitRangeItem checks that requests are not below the item minimums and that limits, or requests
+// where no limit is set, are not above its maximums.
+func withinLimitRangeItem(requests, limits apiv1.ResourceList, item apiv1.LimitRangeItem) bool {
+	for name, min := range item.Min {
+		if request, found := requests[name]; found && request.Cmp(min) < 0 {
+			return false
+		}
+	}
+	for name, max := range item.Max {
+		value, found := limits[name]
+		if !found {
+			value, found = requests[name]
+		}
+		if found && value.Cmp(max) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addResources(sum, resources apiv1.ResourceList) {
+	for name, quantity := range resources {
+		if total, found := sum[name]; found {
+			total.Add(quantity)
+			sum[name] = total
+		} else {
+			sum[name] = *quantity.Copy()
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/resource"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLimitRangeViolatingPods(t *testing.T) {
+	limitRange := &apiv1.LimitRange{
+		ObjectMeta: apiv1.ObjectMeta{Name: "limits", Namespace: "default"},
+		Spec: apiv1.LimitRangeSpec{Limits: []apiv1.LimitRangeItem{
+			{
+				Type: apiv1.LimitTypeContainer,
+				Min:  apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("100m")},
+				Max:  apiv1.ResourceList{apiv1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			{
+				Type: apiv1.LimitTypePod,
+				Max:  apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2")},
+			},
+		}},
+	}
+	buildPod := func(name, namespace string, containers ...apiv1.ResourceRequirements) *apiv1.Pod {
+		pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: namespace}}
+		for _, resources := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, apiv1.Container{Resources: resources})
+		}
+		return pod
+	}
+	requests := func(cpu, memory string) apiv1.ResourceRequirements {
+		return apiv1.ResourceRequirements{Requests: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse(cpu),
+			apiv1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+
+	ok := buildPod("ok", "default", requests("500m", "512Mi"), requests("1", "1Gi"))
+	tooSmall := buildPod("too-small", "default", requests("50m", "512Mi"))
+	tooBig := buildPod("too-big", "default", requests("500m", "2Gi"))
+	limitTooBig := buildPod("limit-too-big", "default", apiv1.ResourceRequirements{
+		Limits: apiv1.ResourceList{apiv1.ResourceMemory: resource.MustParse("2Gi")},
+	})
+	podTooBig := buildPod("pod-too-big", "default", requests("1", "512Mi"), requests("1500m", "512Mi"))
+	otherNamespace := buildPod("other", "other", requests("50m", "2Gi"))
+
+	result, err := GetLimitRangeViolatingPods(context.Background(), fake.NewSimpleClientset(limitRange),
+		[]*apiv1.Pod{ok, tooSmall, tooBig, limitTooBig, podTooBig, otherNamespace})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{tooSmall, tooBig, limitTooBig, podTooBig}, result)
+}
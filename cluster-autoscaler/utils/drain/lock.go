@@ -0,0 +1,115 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/client/leaderelection/resourcelock"
+
+	"github.com/golang/glog"
+)
+
+// AcquireDistributedDrainLock tries to take the drain lock stored on the lockName endpoints object in the
+// given namespace, so that only one autoscaler drains nodes of a shared workload at a time. The lock uses
+// the same record as leader election and is held for ttl, rounded up to whole seconds, unless renewed
+// by calling this function again. Returns false if the lock is held by another, non-expired holder. An
+// error is returned if ttl is shorter than a second.
+func AcquireDistributedDrainLock(ctx context.Context, client client.Interface, namespace, lockName, holderID string,
+	ttl time.Duration) (acquired bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return tryAcquireDrainLock(newDrainLock(client, namespace, lockName, holderID), ttl, time.Now())
+}
+
+// ReleaseDistributedDrainLock releases the drain lock if it is held by holderID.
+func ReleaseDistributedDrainLock(ctx context.Context, client client.Interface, namespace, lockName, holderID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	lock := newDrainLock(client, namespace, lockName, holderID)
+	record, err := lock.Get()
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if record.HolderIdentity != holderID {
+		return nil
+	}
+	record.HolderIdentity = ""
+	return lock.Update(*record)
+}
+
+func newDrainLock(client client.Interface, namespace, lockName, holderID string) resourcelock.Interface {
+	return &resourcelock.EndpointsLock{
+		EndpointsMeta: apiv1.ObjectMeta{
+			Namespace: namespace,
+			Name:      lockName,
+		},
+		Client:     client,
+		LockConfig: resourcelock.ResourceLockConfig{Identity: holderID},
+	}
+}
+
+func tryAcquireDrainLock(lock resourcelock.Interface, ttl time.Duration, now time.Time) (bool, error) {
+	// The lease is stored in whole seconds, a shorter ttl would expire as soon as it is taken.
+	if ttl < time.Second {
+		return false, fmt.Errorf("drain lock ttl must be at least a second, got %v", ttl)
+	}
+	record := resourcelock.LeaderElectionRecord{
+		HolderIdentity:       lock.Identity(),
+		LeaseDurationSeconds: int((ttl + time.Second - 1) / time.Second),
+		AcquireTime:          metav1.NewTime(now),
+		RenewTime:            metav1.NewTime(now),
+	}
+
+	oldRecord, err := lock.Get()
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		if err := lock.Create(record); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	expiry := oldRecord.RenewTime.Add(time.Duration(oldRecord.LeaseDurationSeconds) * time.Second)
+	if oldRecord.HolderIdentity != "" && oldRecord.HolderIdentity != lock.Identity() && expiry.After(now) {
+		glog.V(4).Infof("Drain lock %s is held by %s until %v", lock.Describe(), oldRecord.HolderIdentity, expiry)
+		return false, nil
+	}
+	if oldRecord.HolderIdentity == lock.Identity() {
+		record.AcquireTime = oldRecord.AcquireTime
+		record.LeaderTransitions = oldRecord.LeaderTransitions
+	} else {
+		record.LeaderTransitions = oldRecord.LeaderTransitions + 1
+	}
+	if err := lock.Update(record); err != nil {
+		return false, err
+	}
+	return true, nil
+}
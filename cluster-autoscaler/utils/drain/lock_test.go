@@ -0,0 +1,67 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributedDrainLock(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	ctx := context.Background()
+
+	acquired, err := AcquireDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Renewal by the same holder.
+	acquired, err = AcquireDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = AcquireDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-2", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	// The lock expires after ttl.
+	lock := newDrainLock(fakeClient, "kube-system", "drain-lock", "ca-2")
+	acquired, err = tryAcquireDrainLock(lock, time.Minute, time.Now().Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	assert.NoError(t, ReleaseDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-2"))
+	acquired, err = AcquireDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	_, err = AcquireDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-1", 500*time.Millisecond)
+	assert.Error(t, err)
+
+	// Sub-second parts of the ttl are rounded up.
+	acquired, err = AcquireDistributedDrainLock(ctx, fakeClient, "kube-system", "drain-lock", "ca-1", 1500*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	record, err := newDrainLock(fakeClient, "kube-system", "drain-lock", "ca-1").Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, record.LeaseDurationSeconds)
+}
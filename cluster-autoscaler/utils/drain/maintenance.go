@@ -0,0 +1,127 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// MaintenancePolicyWindowsKey is the ConfigMap key holding the JSON-encoded allowed windows of a
+// MaintenancePolicy, e.g. [{"start": "22:00", "end": "06:00", "timezone": "Europe/Berlin"}].
+const MaintenancePolicyWindowsKey = "allowedWindows"
+
+// TimeWindow is a daily period, given as "15:04" times in a timezone. A window ending before it starts
+// spans midnight.
+type TimeWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Timezone is an IANA timezone name. Empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// MaintenancePolicy defines when nodes may be drained.
+type MaintenancePolicy struct {
+	AllowedWindows []TimeWindow
+}
+
+// IsDrainPermittedByPolicy checks whether now is within one of the allowed windows of the policy. If it
+// isn't, the time until the next allowed window starts is returned as well. A policy without windows
+// permits drains at any time.
+func IsDrainPermittedByPolicy(policy MaintenancePolicy, now time.Time) (bool, time.Duration, error) {
+	if len(policy.AllowedWindows) == 0 {
+		return true, 0, nil
+	}
+	var untilNext time.Duration
+	for i, window := range policy.AllowedWindows {
+		within, untilStart, err := window.check(now)
+		if err != nil {
+			return false, 0, err
+		}
+		if within {
+			return true, 0, nil
+		}
+		if i == 0 || untilStart < untilNext {
+			untilNext = untilStart
+		}
+	}
+	return false, untilNext, nil
+}
+
+// check returns whether now is within the window and, if not, how long until it starts.
+func (w TimeWindow) check(now time.Time) (bool, time.Duration, error) {
+	location := time.UTC
+	if w.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(w.Timezone); err != nil {
+			return false, 0, fmt.Errorf("invalid timezone of window %s-%s: %v", w.Start, w.End, err)
+		}
+	}
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid start of window %s-%s: %v", w.Start, w.End, err)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid end of window %s-%s: %v", w.Start, w.End, err)
+	}
+
+	local := now.In(location)
+	// Check the window starting yesterday too, in case it spans midnight.
+	for _, day := range []int{-1, 0} {
+		windowStart := time.Date(local.Year(), local.Month(), local.Day()+day,
+			start.Hour(), start.Minute(), 0, 0, location)
+		windowEnd := time.Date(local.Year(), local.Month(), local.Day()+day,
+			end.Hour(), end.Minute(), 0, 0, location)
+		if !windowEnd.After(windowStart) {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+		if !local.Before(windowStart) && local.Before(windowEnd) {
+			return true, 0, nil
+		}
+	}
+	nextStart := time.Date(local.Year(), local.Month(), local.Day(), start.Hour(), start.Minute(), 0, 0, location)
+	if !nextStart.After(local) {
+		nextStart = nextStart.AddDate(0, 0, 1)
+	}
+	return false, nextStart.Sub(local), nil
+}
+
+// LoadMaintenancePolicyFromConfigMap reads a MaintenancePolicy from MaintenancePolicyWindowsKey of the
+// given ConfigMap.
+func LoadMaintenancePolicyFromConfigMap(ctx context.Context, client client.Interface,
+	namespace, name string) (MaintenancePolicy, error) {
+	if err := ctx.Err(); err != nil {
+		return MaintenancePolicy{}, err
+	}
+	configMap, err := client.Core().ConfigMaps(namespace).Get(name)
+	if err != nil {
+		return MaintenancePolicy{}, fmt.Errorf("failed to get maintenance policy %s/%s: %v", namespace, name, err)
+	}
+	policy := MaintenancePolicy{}
+	if value, found := configMap.Data[MaintenancePolicyWindowsKey]; found {
+		if err := json.Unmarshal([]byte(value), &policy.AllowedWindows); err != nil {
+			return MaintenancePolicy{}, fmt.Errorf("invalid %s in maintenance policy %s/%s: %v",
+				MaintenancePolicyWindowsKey, namespace, name, err)
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDrainPermittedByPolicy(t *testing.T) {
+	policy := MaintenancePolicy{AllowedWindows: []TimeWindow{
+		{Start: "22:00", End: "06:00"},
+		{Start: "12:00", End: "13:00", Timezone: "UTC"},
+	}}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2017, 1, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	permitted, _, err := IsDrainPermittedByPolicy(policy, at(23, 0))
+	assert.NoError(t, err)
+	assert.True(t, permitted)
+
+	permitted, _, err = IsDrainPermittedByPolicy(policy, at(5, 30))
+	assert.NoError(t, err)
+	assert.True(t, permitted)
+
+	permitted, _, err = IsDrainPermittedByPolicy(policy, at(12, 30))
+	assert.NoError(t, err)
+	assert.True(t, permitted)
+
+	permitted, untilNext, err := IsDrainPermittedByPolicy(policy, at(9, 0))
+	assert.NoError(t, err)
+	assert.False(t, permitted)
+	assert.Equal(t, 3*time.Hour, untilNext)
+
+	permitted, untilNext, err = IsDrainPermittedByPolicy(policy, at(13, 0))
+	assert.NoError(t, err)
+	assert.False(t, permitted)
+	assert.Equal(t, 9*time.Hour, untilNext)
+
+	permitted, _, err = IsDrainPermittedByPolicy(MaintenancePolicy{}, at(9, 0))
+	assert.NoError(t, err)
+	assert.True(t, permitted)
+
+	_, _, err = IsDrainPermittedByPolicy(MaintenancePolicy{AllowedWindows: []TimeWindow{{Start: "9am", End: "17:00"}}}, at(9, 0))
+	assert.Error(t, err)
+	_, _, err = IsDrainPermittedByPolicy(MaintenancePolicy{AllowedWindows: []TimeWindow{
+		{Start: "09:00", End: "17:00", Timezone: "Nowhere/Special"}}}, at(9, 0))
+	assert.Error(t, err)
+}
+
+func TestLoadMaintenancePolicyFromConfigMap(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&apiv1.ConfigMap{
+			ObjectMeta: apiv1.ObjectMeta{Name: "policy", Namespace: "kube-system"},
+			Data:       map[string]string{MaintenancePolicyWindowsKey: `[{"start": "22:00", "end": "06:00", "timezone": "UTC"}]`},
+		},
+		&apiv1.ConfigMap{
+			ObjectMeta: apiv1.ObjectMeta{Name: "broken", Namespace: "kube-system"},
+			Data:       map[string]string{MaintenancePolicyWindowsKey: "22:00-06:00"},
+		})
+
+	policy, err := LoadMaintenancePolicyFromConfigMap(context.Background(), fakeClient, "kube-system", "policy")
+	assert.NoError(t, err)
+	assert.Equal(t, MaintenancePolicy{AllowedWindows: []TimeWindow{{Start: "22:00", End: "06:00", Timezone: "UTC"}}}, policy)
+
+	_, err = LoadMaintenancePolicyFromConfigMap(context.Background(), fakeClient, "kube-system", "broken")
+	assert.Error(t, err)
+	_, err = LoadMaintenancePolicyFromConfigMap(context.Background(), fakeClient, "kube-system", "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"k8s.io/kubernetes/pkg/api/resource"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// GetHighMemoryPods returns pods whose memory request, summed over their containers, exceeds threshold.
+// Such pods may not fit on the remaining nodes, or may push them towards OOM, once they are evicted.
+func GetHighMemoryPods(pods []*apiv1.Pod, threshold resource.Quantity) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		memory := podMemoryRequest(pod)
+		if memory.Cmp(threshold) > 0 {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// TotalMemoryRequests returns the sum of memory requests of the pods, i.e. the memory that the remaining
+// nodes would have to absorb when the pods are rescheduled.
+func TotalMemoryRequests(pods []*apiv1.Pod) resource.Quantity {
+	total := resource.MustParse("0")
+	for _, pod := range pods {
+		memory := podMemoryRequest(pod)
+		total.Add(memory)
+	}
+	return total
+}
+
+func podMemoryRequest(pod *apiv1.Pod) resource.Quantity {
+	requests := apiv1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	if memory, found := requests[apiv1.ResourceMemory]; found {
+		return memory
+	}
+	return resource.MustParse("0")
+}
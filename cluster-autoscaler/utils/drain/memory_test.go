@@ -0,0 +1,57 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/resource"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHighMemoryPods(t *testing.T) {
+	buildPod := func(name string, memory ...string) *apiv1.Pod {
+		pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"}}
+		for _, m := range memory {
+			pod.Spec.Containers = append(pod.Spec.Containers, apiv1.Container{
+				Resources: apiv1.ResourceRequirements{Requests: apiv1.ResourceList{
+					apiv1.ResourceMemory: resource.MustParse(m),
+				}},
+			})
+		}
+		return pod
+	}
+	small := buildPod("small", "512Mi")
+	large := buildPod("large", "4Gi")
+	sidecars := buildPod("sidecars", "1Gi", "1536Mi")
+	atThreshold := buildPod("at-threshold", "2Gi")
+	noRequests := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "no-requests", Namespace: "default"},
+		Spec:       apiv1.PodSpec{Containers: []apiv1.Container{{Name: "app"}}},
+	}
+	pods := []*apiv1.Pod{small, large, sidecars, atThreshold, noRequests}
+
+	assert.Equal(t, []*apiv1.Pod{large, sidecars}, GetHighMemoryPods(pods, resource.MustParse("2Gi")))
+	assert.Empty(t, GetHighMemoryPods(pods, resource.MustParse("8Gi")))
+
+	total := TotalMemoryRequests(pods)
+	assert.Equal(t, 0, total.Cmp(resource.MustParse("9Gi")))
+	total = TotalMemoryRequests([]*apiv1.Pod{noRequests})
+	assert.True(t, total.IsZero())
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+const (
+	// PreStopGraceAnnotation holds the extra time, as a Go duration, a mesh-managed pod needs to drain
+	// its in-flight connections before it is evicted.
+	PreStopGraceAnnotation = "cluster-autoscaler.kubernetes.io/prestop-grace"
+)
+
+// serviceMeshSidecars lists the names of the proxy containers injected by service meshes.
+var serviceMeshSidecars = map[string]bool{
+	"envoy":         true,
+	"linkerd-proxy": true,
+}
+
+// GetServiceMeshSidecarPods returns the pods that run a service mesh proxy container.
+func GetServiceMeshSidecarPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if hasServiceMeshSidecar(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func hasServiceMeshSidecar(pod *apiv1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if serviceMeshSidecars[container.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPreStopGrace returns how long to wait before evicting a mesh-managed pod. The value of
+// PreStopGraceAnnotation takes precedence over defaultGrace. Pods without a mesh proxy get 0.
+func GetPreStopGrace(pod *apiv1.Pod, defaultGrace time.Duration) (time.Duration, error) {
+	if !hasServiceMeshSidecar(pod) {
+		return 0, nil
+	}
+	value, found := pod.ObjectMeta.Annotations[PreStopGraceAnnotation]
+	if !found {
+		return defaultGrace, nil
+	}
+	grace, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s annotation of %s/%s: %v",
+			PreStopGraceAnnotation, pod.Namespace, pod.Name, err)
+	}
+	return grace, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildPodWithContainers(name string, annotations map[string]string, containers ...string) *apiv1.Pod {
+	pod := buildAnnotatedPod(name, annotations)
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, apiv1.Container{Name: container})
+	}
+	return pod
+}
+
+func TestGetServiceMeshSidecarPods(t *testing.T) {
+	envoyPod := buildPodWithContainers("envoy", nil, "app", "envoy")
+	linkerdPod := buildPodWithContainers("linkerd", nil, "linkerd-proxy", "app")
+	plainPod := buildPodWithContainers("plain", nil, "app")
+
+	assert.Equal(t, []*apiv1.Pod{envoyPod, linkerdPod},
+		GetServiceMeshSidecarPods([]*apiv1.Pod{envoyPod, plainPod, linkerdPod}))
+}
+
+func TestGetPreStopGrace(t *testing.T) {
+	meshPod := buildPodWithContainers("mesh", nil, "app", "envoy")
+	annotatedPod := buildPodWithContainers("annotated", map[string]string{PreStopGraceAnnotation: "45s"}, "envoy")
+	brokenPod := buildPodWithContainers("broken", map[string]string{PreStopGraceAnnotation: "soon"}, "envoy")
+	plainPod := buildPodWithContainers("plain", map[string]string{PreStopGraceAnnotation: "45s"}, "app")
+
+	grace, err := GetPreStopGrace(meshPod, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, grace)
+
+	grace, err = GetPreStopGrace(annotatedPod, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 45*time.Second, grace)
+
+	_, err = GetPreStopGrace(brokenPod, 10*time.Second)
+	assert.Error(t, err)
+
+	grace, err = GetPreStopGrace(plainPod, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), grace)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// ErrInsufficientReadyNodes is returned by VerifyTargetNodesHealthy when too few nodes can receive
+// the evicted pods.
+var ErrInsufficientReadyNodes = fmt.Errorf("not enough ready nodes to receive evicted pods")
+
+// VerifyTargetNodesHealthy counts the Ready, schedulable nodes other than drainingNode and checks that
+// there are at least requiredReadyNodes of them, so that a drain doesn't make a degraded cluster worse.
+// It returns the check result together with the number of healthy nodes found.
+func VerifyTargetNodesHealthy(ctx context.Context, client client.Interface, drainingNode string,
+	requiredReadyNodes int) (bool, int, error) {
+	if err := ctx.Err(); err != nil {
+		return false, 0, err
+	}
+	nodes, err := client.Core().Nodes().List(apiv1.ListOptions{})
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	ready := 0
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Name == drainingNode || node.Spec.Unschedulable {
+			continue
+		}
+		if isNodeReady(node) {
+			ready++
+		}
+	}
+	if ready < requiredReadyNodes {
+		return false, ready, ErrInsufficientReadyNodes
+	}
+	return true, ready, nil
+}
+
+func isNodeReady(node *apiv1.Node) bool {
+	return nodeReadyStatus(node) == apiv1.ConditionTrue
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyTargetNodesHealthy(t *testing.T) {
+	buildNode := func(name string, ready apiv1.ConditionStatus, unschedulable bool) *apiv1.Node {
+		return &apiv1.Node{
+			ObjectMeta: apiv1.ObjectMeta{Name: name},
+			Spec:       apiv1.NodeSpec{Unschedulable: unschedulable},
+			Status: apiv1.NodeStatus{
+				Conditions: []apiv1.NodeCondition{{Type: apiv1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	fakeClient := fake.NewSimpleClientset(
+		buildNode("draining", apiv1.ConditionTrue, false),
+		buildNode("n1", apiv1.ConditionTrue, false),
+		buildNode("n2", apiv1.ConditionTrue, false),
+		buildNode("notready", apiv1.ConditionFalse, false),
+		buildNode("cordoned", apiv1.ConditionTrue, true))
+
+	healthy, ready, err := VerifyTargetNodesHealthy(context.Background(), fakeClient, "draining", 2)
+	assert.NoError(t, err)
+	assert.True(t, healthy)
+	assert.Equal(t, 2, ready)
+
+	healthy, ready, err = VerifyTargetNodesHealthy(context.Background(), fakeClient, "draining", 3)
+	assert.Equal(t, ErrInsufficientReadyNodes, err)
+	assert.False(t, healthy)
+	assert.Equal(t, 2, ready)
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// OperatorComponentLabel holds the name of the component of an operator-managed application the pod
+// belongs to, e.g. "database-primary".
+const OperatorComponentLabel = "operator.kubernetes.io/component"
+
+// GetOperatorComponentPods groups pods by the value of their OperatorComponentLabel, so that the impact
+// of a drain can be reported per operator component. Pods without the label are not returned.
+func GetOperatorComponentPods(pods []*apiv1.Pod) map[string][]*apiv1.Pod {
+	result := map[string][]*apiv1.Pod{}
+	for _, pod := range pods {
+		component := pod.ObjectMeta.Labels[OperatorComponentLabel]
+		if component == "" {
+			continue
+		}
+		result[component] = append(result[component], pod)
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOperatorComponentPods(t *testing.T) {
+	primary1 := buildReplicatedPod("primary-1", map[string]string{OperatorComponentLabel: "database-primary"})
+	primary2 := buildReplicatedPod("primary-2", map[string]string{OperatorComponentLabel: "database-primary"})
+	replica := buildReplicatedPod("replica", map[string]string{OperatorComponentLabel: "database-replica"})
+	plain := buildReplicatedPod("plain", map[string]string{"app": "plain"})
+	unlabeled := buildReplicatedPod("unlabeled", nil)
+
+	result := GetOperatorComponentPods([]*apiv1.Pod{primary1, plain, replica, unlabeled, primary2})
+	assert.Equal(t, map[string][]*apiv1.Pod{
+		"database-primary": {primary1, primary2},
+		"database-replica": {replica},
+	}, result)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"sort"
+	"strconv"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/kubelet/qos"
+
+	"github.com/golang/glog"
+)
+
+// PodDeletionCostAnnotation is the standard annotation (from Kubernetes 1.22) with the int32 cost of
+// deleting a pod relative to other pods of its controller.
+const PodDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+
+// podsByScore sorts pods by descending score keeping the original order of pods with equal scores.
+type podsByScore struct {
+	pods   []*apiv1.Pod
+	scores []int
+}
+
+func (p podsByScore) Len() int           { return len(p.pods) }
+func (p podsByScore) Less(i, j int) bool { return p.scores[i] > p.scores[j] }
+func (p podsByScore) Swap(i, j int) {
+	p.pods[i], p.pods[j] = p.pods[j], p.pods[i]
+	p.scores[i], p.scores[j] = p.scores[j], p.scores[i]
+}
+
+// sortPodsByScore returns a copy of pods stably sorted by descending score.
+func sortPodsByScore(pods []*apiv1.Pod, score func(*apiv1.Pod) int) []*apiv1.Pod {
+	sorted := podsByScore{
+		pods:   make([]*apiv1.Pod, len(pods)),
+		scores: make([]int, len(pods)),
+	}
+	for i, pod := range pods {
+		sorted.pods[i] = pod
+		sorted.scores[i] = score(pod)
+	}
+	sort.Stable(sorted)
+	return sorted.pods
+}
+
+// SortPodsByEvictionSafety returns pods ordered from the safest to the riskiest to evict. A pod is
+// safer the lower its QoS class is, the less it is constrained by PodDisruptionBudgets and if its
+// controller has other ready replicas. The replicas are taken from the status of the controller, fetched
+// with client. Pods whose controller can't be fetched count as single replicas. Pods with equal safety
+// keep their order.
+func SortPodsByEvictionSafety(pods []*apiv1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget,
+	client client.Interface) []*apiv1.Pod {
+	replicas := make(map[string]int32)
+	for _, pod := range pods {
+		key := creatorKey(pod)
+		if _, found := replicas[key]; key == "" || found {
+			continue
+		}
+		ready, err := controllerReadyReplicas(pod, client)
+		if err != nil {
+			glog.Warningf("Failed to get replicas of the controller of %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		replicas[key] = ready
+	}
+	return sortPodsByScore(pods, func(pod *apiv1.Pod) int {
+		return evictionSafetyScore(pod, pdbs, replicas[creatorKey(pod)])
+	})
+}
+
+// controllerReadyReplicas returns the number of ready replicas of the controller of the pod. StatefulSets
+// don't report ready replicas in this API version, so their current replicas are returned. Returns 0 for
+// pods without a controller or with a controller that doesn't have replicas.
+func controllerReadyReplicas(pod *apiv1.Pod, client client.Interface) (int32, error) {
+	sr, err := CreatorRef(pod)
+	if err != nil || sr == nil {
+		return 0, err
+	}
+	switch sr.Reference.Kind {
+	case "ReplicationController":
+		rc, err := client.Core().ReplicationControllers(sr.Reference.Namespace).Get(sr.Reference.Name)
+		if err != nil {
+			return 0, err
+		}
+		return rc.Status.ReadyReplicas, nil
+	case "ReplicaSet":
+		rs, err := client.Extensions().ReplicaSets(sr.Reference.Namespace).Get(sr.Reference.Name)
+		if err != nil {
+			return 0, err
+		}
+		return rs.Status.ReadyReplicas, nil
+	case "StatefulSet":
+		ss, err := client.Apps().StatefulSets(sr.Reference.Namespace).Get(sr.Reference.Name)
+		if err != nil {
+			return 0, err
+		}
+		return ss.Status.Replicas, nil
+	}
+	return 0, nil
+}
+
+func evictionSafetyScore(pod *apiv1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget, replicas int32) int {
+	score := 0
+	switch qos.GetPodQOS(pod) {
+	case qos.BestEffort:
+		score += 2
+	case qos.Burstable:
+		score++
+	}
+	podPDBs := GetPodPDBs(pod, pdbs)
+	if len(podPDBs) == 0 {
+		score += 2
+	} else if !isBlockedByPDB(pod, podPDBs) {
+		score++
+	}
+	if replicas > 1 {
+		score++
+	}
+	return score
+}
+
+// creatorKey identifies the controller of the pod, or returns an empty string for pods without one.
+func creatorKey(pod *apiv1.Pod) string {
+	sr, err := CreatorRef(pod)
+	if err != nil || sr == nil {
+		return ""
+	}
+	return sr.Reference.Kind + "/" + sr.Reference.Namespace + "/" + sr.Reference.Name
+}
+
+// SortPodsByDrainPriority returns pods in eviction order according to DrainPriorityAnnotation: "high"
+// priority pods come first, "low" priority pods last and pods without the annotation (or with
+// any other value) in between. Pods with equal priority keep their order.
+func SortPodsByDrainPriority(pods []*apiv1.Pod) []*apiv1.Pod {
+	return sortPodsByScore(pods, func(pod *apiv1.Pod) int {
+		switch pod.ObjectMeta.Annotations[DrainPriorityAnnotation] {
+		case "high":
+			return 1
+		case "low":
+			return -1
+		}
+		return 0
+	})
+}
+
+// SortPodsByDisruptionCost returns pods in ascending order of PodDeletionCostAnnotation, so that the pods
+// cheapest to disrupt are evicted first. Pods without the annotation, or with an invalid one, have the
+// default cost of 0. Pods with equal cost are ordered by SortPodsByDrainPriority.
+func SortPodsByDisruptionCost(pods []*apiv1.Pod) []*apiv1.Pod {
+	return sortPodsByScore(SortPodsByDrainPriority(pods), func(pod *apiv1.Pod) int {
+		value, found := pod.ObjectMeta.Annotations[PodDeletionCostAnnotation]
+		if !found {
+			return 0
+		}
+		cost, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			glog.Warningf("Invalid %s annotation on pod %s/%s: %v", PodDeletionCostAnnotation, pod.Namespace, pod.Name, err)
+			return 0
+		}
+		return -int(cost)
+	})
+}
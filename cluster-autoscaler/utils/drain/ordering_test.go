@@ -0,0 +1,81 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	. "k8s.io/contrib/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortPodsByEvictionSafety(t *testing.T) {
+	guaranteed := BuildTestPod("guaranteed", 100, 1000)
+	guaranteed.Spec.Containers[0].Resources.Limits = guaranteed.Spec.Containers[0].Resources.Requests
+	guaranteed.Labels = map[string]string{"app": "db"}
+
+	burstable := BuildTestPod("burstable", 100, 1000)
+	bestEffort1 := BuildTestPod("besteffort1", -1, -1)
+	bestEffort2 := BuildTestPod("besteffort2", -1, -1)
+	for _, pod := range []*apiv1.Pod{burstable, bestEffort1, bestEffort2} {
+		pod.Annotations = buildReplicaSetPod(pod.Name, "web").Annotations
+	}
+	// The only other replica of besteffort2 is on another node, yet it is ranked by the replicas of its
+	// controller rather than the co-located ones.
+	bestEffort2.Annotations = buildReplicaSetPod(bestEffort2.Name, "single").Annotations
+	web := buildTestReplicaSet("web", "1", 3)
+	web.Status.ReadyReplicas = 3
+	single := buildTestReplicaSet("single", "1", 1)
+	single.Status.ReadyReplicas = 1
+	fakeClient := fake.NewSimpleClientset(web, single)
+	pdbs := []*policyv1beta1.PodDisruptionBudget{buildTestPDB("db", map[string]string{"app": "db"}, 0)}
+
+	sorted := SortPodsByEvictionSafety([]*apiv1.Pod{guaranteed, bestEffort2, burstable, bestEffort1}, pdbs, fakeClient)
+	assert.Equal(t, []*apiv1.Pod{bestEffort1, bestEffort2, burstable, guaranteed}, sorted)
+
+	// Controllers that can't be fetched count as single replicas.
+	sorted = SortPodsByEvictionSafety([]*apiv1.Pod{bestEffort2, burstable, bestEffort1}, pdbs, fake.NewSimpleClientset())
+	assert.Equal(t, []*apiv1.Pod{bestEffort2, bestEffort1, burstable}, sorted)
+}
+
+func TestSortPodsByDrainPriority(t *testing.T) {
+	low := buildAnnotatedPod("low", map[string]string{DrainPriorityAnnotation: "low"})
+	normal1 := buildAnnotatedPod("normal1", nil)
+	high1 := buildAnnotatedPod("high1", map[string]string{DrainPriorityAnnotation: "high"})
+	normal2 := buildAnnotatedPod("normal2", map[string]string{DrainPriorityAnnotation: "whatever"})
+	high2 := buildAnnotatedPod("high2", map[string]string{DrainPriorityAnnotation: "high"})
+
+	sorted := SortPodsByDrainPriority([]*apiv1.Pod{low, normal1, high1, normal2, high2})
+	assert.Equal(t, []*apiv1.Pod{high1, high2, normal1, normal2, low}, sorted)
+}
+
+func TestSortPodsByDisruptionCost(t *testing.T) {
+	expensive := buildAnnotatedPod("expensive", map[string]string{PodDeletionCostAnnotation: "100"})
+	normal := buildAnnotatedPod("normal", nil)
+	cheap := buildAnnotatedPod("cheap", map[string]string{PodDeletionCostAnnotation: "-10"})
+	high := buildAnnotatedPod("high", map[string]string{DrainPriorityAnnotation: "high"})
+	invalid := buildAnnotatedPod("invalid", map[string]string{PodDeletionCostAnnotation: "lots"})
+	zero := buildAnnotatedPod("zero", map[string]string{PodDeletionCostAnnotation: "0", DrainPriorityAnnotation: "low"})
+
+	sorted := SortPodsByDisruptionCost([]*apiv1.Pod{expensive, normal, cheap, high, invalid, zero})
+	assert.Equal(t, []*apiv1.Pod{cheap, high, normal, invalid, zero, expensive}, sorted)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/labels"
+
+	"github.com/golang/glog"
+)
+
+// ColocatedWithAnnotation groups pods that are only healthy when running on the same node.
+const ColocatedWithAnnotation = "pod.kubernetes.io/colocated-with"
+
+// DefaultKnownOwnerKinds lists the controller kinds that recreate their pods after eviction.
+var DefaultKnownOwnerKinds = []string{
+	"ReplicationController",
+	"ReplicaSet",
+	"DaemonSet",
+	"Job",
+	"StatefulSet",
+	"CronJob",
+}
+
+// FindNakedPods returns pods that are not managed by any of the known controller kinds and
+// therefore will not be recreated after eviction. If knownKinds is empty DefaultKnownOwnerKinds
+// is used. If client is not nil the existence of built-in owners is verified as well and pods
+// whose owner is gone are reported as naked. Mirror pods are never reported.
+func FindNakedPods(pods []*apiv1.Pod, client client.Interface, knownKinds []string) ([]*apiv1.Pod, error) {
+	if len(knownKinds) == 0 {
+		knownKinds = DefaultKnownOwnerKinds
+	}
+	known := make(map[string]bool, len(knownKinds))
+	for _, kind := range knownKinds {
+		known[kind] = true
+	}
+
+	naked := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if IsMirrorPod(pod) {
+			continue
+		}
+		sr, err := CreatorRef(pod)
+		if err != nil {
+			return []*apiv1.Pod{}, fmt.Errorf("failed to obtain refkind for %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if sr == nil || !known[sr.Reference.Kind] {
+			naked = append(naked, pod)
+			continue
+		}
+		if client == nil {
+			continue
+		}
+		exists, err := ownerExists(client, &sr.Reference)
+		if err != nil {
+			return []*apiv1.Pod{}, fmt.Errorf("failed to get %s for %s/%s: %v", sr.Reference.Kind, pod.Namespace, pod.Name, err)
+		}
+		if !exists {
+			naked = append(naked, pod)
+		}
+	}
+	return naked, nil
+}
+
+// GetColocatedReplicaPods groups the pods by their controller and returns the groups with more than one
+// member, in the order of their first pod. Draining a node running such a group takes down several
+// replicas at once. Pods without a controller are ignored.
+func GetColocatedReplicaPods(pods []*apiv1.Pod) [][]*apiv1.Pod {
+	groups := make(map[string][]*apiv1.Pod)
+	keys := []string{}
+	for _, pod := range pods {
+		key := creatorKey(pod)
+		if key == "" {
+			continue
+		}
+		if _, found := groups[key]; !found {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pod)
+	}
+	result := [][]*apiv1.Pod{}
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			result = append(result, groups[key])
+		}
+	}
+	return result
+}
+
+// GetCoLocatedDependentPods groups the pods by the value of their affinityLabel annotation
+// (ColocatedWithAnnotation if empty) and returns the groups with more than one member, in the order of
+// their first pod. A warning is logged for groups mixing evictable pods with DaemonSet or mirror pods,
+// which stay on the node and get separated from their companions.
+func GetCoLocatedDependentPods(pods []*apiv1.Pod, affinityLabel string) [][]*apiv1.Pod {
+	if affinityLabel == "" {
+		affinityLabel = ColocatedWithAnnotation
+	}
+	groups := make(map[string][]*apiv1.Pod)
+	keys := []string{}
+	for _, pod := range pods {
+		key := pod.ObjectMeta.Annotations[affinityLabel]
+		if key == "" {
+			continue
+		}
+		if _, found := groups[key]; !found {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pod)
+	}
+	result := [][]*apiv1.Pod{}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		staying := 0
+		for _, pod := range group {
+			if refKind, err := CreatorRefKind(pod); IsMirrorPod(pod) || (err == nil && refKind == "DaemonSet") {
+				staying++
+			}
+		}
+		if staying > 0 && staying < len(group) {
+			glog.Warningf("Evicting pods co-located with %s would leave their companion pods behind", key)
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+// ownerExists checks whether the referenced built-in controller is still present. Kinds that
+// cannot be fetched with the typed client (e.g. custom resources) are assumed to exist.
+func ownerExists(client client.Interface, ref *apiv1.ObjectReference) (bool, error) {
+	var err error
+	switch ref.Kind {
+	case "ReplicationController":
+		_, err = client.Core().ReplicationControllers(ref.Namespace).Get(ref.Name)
+	case "ReplicaSet":
+		_, err = client.Extensions().ReplicaSets(ref.Namespace).Get(ref.Name)
+	case "DaemonSet":
+		_, err = client.Extensions().DaemonSets(ref.Namespace).Get(ref.Name)
+	case "Job":
+		_, err = client.Batch().Jobs(ref.Namespace).Get(ref.Name)
+	case "StatefulSet":
+		_, err = client.Apps().StatefulSets(ref.Namespace).Get(ref.Name)
+	case "CronJob":
+		_, err = client.BatchV2alpha1().CronJobs(ref.Namespace).Get(ref.Name)
+	default:
+		return true, nil
+	}
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deploymentForPod returns the deployment from the given list whose selector matches the pod, or nil
+// if there is none. Pods created by a deployment reference its replica set, so the deployment
+// has to be found by its selector.
+func deploymentForPod(deployments []extensions.Deployment, pod *apiv1.Pod) (*extensions.Deployment, error) {
+	return deploymentForLabels(deployments, pod.Namespace, pod.Labels)
+}
+
+// deploymentForLabels returns the deployment from the given list in namespace whose selector matches
+// objectLabels, or nil if there is none. Deployments with an empty selector match nothing.
+func deploymentForLabels(deployments []extensions.Deployment, namespace string,
+	objectLabels map[string]string) (*extensions.Deployment, error) {
+	for i := range deployments {
+		deployment := &deployments[i]
+		if deployment.Namespace != namespace || deployment.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+		}
+		if !selector.Empty() && selector.Matches(labels.Set(objectLabels)) {
+			return deployment, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/testapi"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+	kubelettypes "k8s.io/kubernetes/pkg/kubelet/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindNakedPods(t *testing.T) {
+	rs := extensions.ReplicaSet{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			SelfLink:  testapi.Default.SelfLink("replicasets", "rs"),
+		},
+	}
+	goneRs := extensions.ReplicaSet{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "gone",
+			Namespace: "default",
+			SelfLink:  testapi.Default.SelfLink("replicasets", "gone"),
+		},
+	}
+
+	rsPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "rsPod",
+			Namespace:   "default",
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: refJSON(t, &rs)},
+		},
+	}
+	orphanedPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "orphanedPod",
+			Namespace:   "default",
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: refJSON(t, &goneRs)},
+		},
+	}
+	customPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "customPod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				apiv1.CreatedByAnnotation: "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"EtcdCluster\"}}",
+			},
+		},
+	}
+	nakedPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "nakedPod",
+			Namespace: "default",
+		},
+	}
+	mirrorPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "mirrorPod",
+			Namespace:   "kube-system",
+			Annotations: map[string]string{kubelettypes.ConfigMirrorAnnotationKey: "something"},
+		},
+	}
+	pods := []*apiv1.Pod{rsPod, orphanedPod, customPod, nakedPod, mirrorPod}
+
+	naked, err := FindNakedPods(pods, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{customPod, nakedPod}, naked)
+
+	naked, err = FindNakedPods(pods, nil, append([]string{"EtcdCluster"}, DefaultKnownOwnerKinds...))
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{nakedPod}, naked)
+
+	naked, err = FindNakedPods(pods, fake.NewSimpleClientset(&rs), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{orphanedPod, customPod, nakedPod}, naked)
+}
+
+func TestGetColocatedReplicaPods(t *testing.T) {
+	otherRsCreatedBy := "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"ReplicaSet\",\"name\":\"other\"}}"
+	web1 := buildReplicatedPod("web1", nil)
+	web2 := buildReplicatedPod("web2", nil)
+	other := buildReplicatedPod("other", nil)
+	other.Annotations[apiv1.CreatedByAnnotation] = otherRsCreatedBy
+	naked1 := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "naked1", Namespace: "default"}}
+	naked2 := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "naked2", Namespace: "default"}}
+
+	groups := GetColocatedReplicaPods([]*apiv1.Pod{web1, naked1, other, web2, naked2})
+	assert.Equal(t, [][]*apiv1.Pod{{web1, web2}}, groups)
+}
+
+func TestGetCoLocatedDependentPods(t *testing.T) {
+	cache := buildReplicatedPod("cache", nil)
+	cache.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	cache.Annotations[ColocatedWithAnnotation] = "cache"
+	app := buildReplicatedPod("app", nil)
+	app.Annotations[ColocatedWithAnnotation] = "cache"
+	lonely := buildReplicatedPod("lonely", nil)
+	lonely.Annotations[ColocatedWithAnnotation] = "other"
+	plain := buildReplicatedPod("plain", nil)
+	custom1 := buildReplicatedPod("custom1", nil)
+	custom1.Annotations["example.com/pair"] = "a"
+	custom2 := buildReplicatedPod("custom2", nil)
+	custom2.Annotations["example.com/pair"] = "a"
+
+	pods := []*apiv1.Pod{cache, lonely, plain, app, custom1, custom2}
+	assert.Equal(t, [][]*apiv1.Pod{{cache, app}}, GetCoLocatedDependentPods(pods, ""))
+	assert.Equal(t, [][]*apiv1.Pod{{custom1, custom2}}, GetCoLocatedDependentPods(pods, "example.com/pair"))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"sort"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// nodesPerParallelDrain is the number of cluster nodes needed for each node drained in parallel.
+const nodesPerParallelDrain = 20
+
+// RecommendedDrainParallelism returns how many nodes can be drained in parallel: one per nodesPerParallelDrain
+// nodes in the cluster, but no more than nodesPerZone (so that a whole zone is never drained at once) and
+// opts.MaxSimultaneousDrains, if set. Non-positive nodesPerZone is ignored. The result is at least 1.
+func RecommendedDrainParallelism(totalNodes, nodesPerZone int, opts DrainOptions) int {
+	result := totalNodes / nodesPerParallelDrain
+	if nodesPerZone > 0 && nodesPerZone < result {
+		result = nodesPerZone
+	}
+	if opts.MaxSimultaneousDrains > 0 && opts.MaxSimultaneousDrains < result {
+		result = opts.MaxSimultaneousDrains
+	}
+	if result < 1 {
+		return 1
+	}
+	return result
+}
+
+// MaxParallelDrainsForQuorum returns how many of the nodes can be drained in parallel so that every
+// quorum group keeps at least quorumSize members. pods[i] are the pods running on nodes[i]. Pods of
+// the same StatefulSet form a quorum group. Nodes are picked first-fit, starting with those hosting the
+// fewest quorum members, so the result is a lower bound on the optimum.
+func MaxParallelDrainsForQuorum(nodes []*apiv1.Node, pods [][]*apiv1.Pod, quorumSize int) int {
+	if len(pods) < len(nodes) {
+		nodes = nodes[:len(pods)]
+	}
+	groupSize := map[string]int{}
+	members := make([]map[string]int, len(nodes))
+	for i := range nodes {
+		members[i] = map[string]int{}
+		for _, pod := range pods[i] {
+			sr, err := CreatorRef(pod)
+			if err != nil || sr == nil || sr.Reference.Kind != "StatefulSet" {
+				continue
+			}
+			group := sr.Reference.Namespace + "/" + sr.Reference.Name
+			members[i][group]++
+			groupSize[group]++
+		}
+	}
+
+	order := make([]int, len(nodes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Stable(byMemberCount{order: order, members: members})
+
+	lost := map[string]int{}
+	result := 0
+	for _, i := range order {
+		fits := true
+		for group, count := range members[i] {
+			if groupSize[group]-lost[group]-count < quorumSize {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			continue
+		}
+		for group, count := range members[i] {
+			lost[group] += count
+		}
+		result++
+	}
+	return result
+}
+
+// byMemberCount sorts node indices by the number of quorum members on the node.
+type byMemberCount struct {
+	order   []int
+	members []map[string]int
+}
+
+func (b byMemberCount) Len() int      { return len(b.order) }
+func (b byMemberCount) Swap(i, j int) { b.order[i], b.order[j] = b.order[j], b.order[i] }
+func (b byMemberCount) Less(i, j int) bool {
+	return memberCount(b.members[b.order[i]]) < memberCount(b.members[b.order[j]])
+}
+
+func memberCount(members map[string]int) int {
+	count := 0
+	for _, c := range members {
+		count += c
+	}
+	return count
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendedDrainParallelism(t *testing.T) {
+	assert.Equal(t, 1, RecommendedDrainParallelism(10, 5, DrainOptions{}))
+	assert.Equal(t, 5, RecommendedDrainParallelism(100, 50, DrainOptions{}))
+	assert.Equal(t, 3, RecommendedDrainParallelism(100, 3, DrainOptions{}))
+	assert.Equal(t, 5, RecommendedDrainParallelism(100, 0, DrainOptions{}))
+	assert.Equal(t, 2, RecommendedDrainParallelism(100, 50, DrainOptions{MaxSimultaneousDrains: 2}))
+}
+
+func TestMaxParallelDrainsForQuorum(t *testing.T) {
+	statefulPod := func(name, set string) *apiv1.Pod {
+		pod := buildReplicatedPod(name, nil)
+		pod.Annotations[apiv1.CreatedByAnnotation] = "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\"," +
+			"\"reference\":{\"kind\":\"StatefulSet\",\"namespace\":\"default\",\"name\":\"" + set + "\"}}"
+		return pod
+	}
+	nodes := []*apiv1.Node{}
+	for _, name := range []string{"n1", "n2", "n3", "n4", "n5"} {
+		nodes = append(nodes, &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: name}})
+	}
+	// etcd has 3 members on n1-n3, zk has 5 members on n1-n5 (two of them on n1).
+	pods := [][]*apiv1.Pod{
+		{statefulPod("etcd-0", "etcd"), statefulPod("zk-0", "zk"), statefulPod("zk-1", "zk")},
+		{statefulPod("etcd-1", "etcd"), statefulPod("zk-2", "zk")},
+		{statefulPod("etcd-2", "etcd"), statefulPod("zk-3", "zk")},
+		{statefulPod("zk-4", "zk")},
+		{buildReplicatedPod("web", nil)},
+	}
+
+	// n5 hosts no quorum members, n4 and one etcd node can go while both keep 2 members.
+	assert.Equal(t, 3, MaxParallelDrainsForQuorum(nodes, pods, 2))
+	// Nothing but n5 can go when zk needs all members.
+	assert.Equal(t, 1, MaxParallelDrainsForQuorum(nodes, pods, 5))
+	assert.Equal(t, 5, MaxParallelDrainsForQuorum(nodes, pods, 0))
+}
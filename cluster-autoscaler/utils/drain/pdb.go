@@ -0,0 +1,175 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/golang/glog"
+)
+
+// ComputeNodeDisruptionScore returns a number between 0 and 1 describing how disruptive draining the node
+// would be. 0 means that all pods can be freely evicted, 1 that every pod is either blocked by a
+// PodDisruptionBudget that allows no more disruptions or is not managed by any controller.
+// Mirror and DaemonSet pods are not taken into account as they are not evicted.
+func ComputeNodeDisruptionScore(node *apiv1.Node, pods []*apiv1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget) float64 {
+	considered := 0
+	constrained := 0
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" && pod.Spec.NodeName != node.Name {
+			continue
+		}
+		if IsMirrorPod(pod) {
+			continue
+		}
+		refKind, err := CreatorRefKind(pod)
+		if err != nil {
+			glog.Warningf("Failed to obtain refkind for %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if refKind == "DaemonSet" {
+			continue
+		}
+		considered++
+		if refKind == "" || isBlockedByPDB(pod, pdbs) {
+			constrained++
+		}
+	}
+	if considered == 0 {
+		return 0
+	}
+	return float64(constrained) / float64(considered)
+}
+
+// ComputePDBBlastRadius returns how many pods covered by pdb draining the node would disrupt and how many
+// disruptions the budget allows. The allowed number is computed from the ready covered pods in allPods and
+// the budget's MinAvailable, so it doesn't depend on the PDB status being up to date. Draining the node
+// exceeds the budget if currentDisruptions > allowedDisruptions.
+func ComputePDBBlastRadius(node *apiv1.Node, nodePods []*apiv1.Pod, allPods []*apiv1.Pod,
+	pdb *policyv1beta1.PodDisruptionBudget) (currentDisruptions, allowedDisruptions int) {
+	for _, pod := range nodePods {
+		if (pod.Spec.NodeName == "" || pod.Spec.NodeName == node.Name) && pdbMatchesPod(pdb, pod) {
+			currentDisruptions++
+		}
+	}
+
+	covered := 0
+	healthy := 0
+	for _, pod := range allPods {
+		if !pdbMatchesPod(pdb, pod) {
+			continue
+		}
+		covered++
+		if podConditionIsTrue(pod, apiv1.PodReady) {
+			healthy++
+		}
+	}
+	minAvailable, err := intstr.GetValueFromIntOrPercent(&pdb.Spec.MinAvailable, covered, true)
+	if err != nil {
+		glog.Warningf("Invalid minAvailable in pod disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+		return currentDisruptions, 0
+	}
+	if healthy > minAvailable {
+		allowedDisruptions = healthy - minAvailable
+	}
+	return currentDisruptions, allowedDisruptions
+}
+
+// GetPodPDBs returns PodDisruptionBudgets from the given list that cover the pod.
+func GetPodPDBs(pod *apiv1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget) []*policyv1beta1.PodDisruptionBudget {
+	result := []*policyv1beta1.PodDisruptionBudget{}
+	for _, pdb := range pdbs {
+		if pdbMatchesPod(pdb, pod) {
+			result = append(result, pdb)
+		}
+	}
+	return result
+}
+
+// GetZeroUnavailablePDBPods returns pods covered by a PodDisruptionBudget whose minAvailable leaves no room for
+// disruptions given the number of pods it expects, e.g. "100%" or equal to the replica count. Unlike a
+// budget that temporarily allows no disruptions, such a budget blocks the drain until it is changed.
+func GetZeroUnavailablePDBPods(ctx context.Context, client client.Interface, pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	pdbs := map[string][]*policyv1beta1.PodDisruptionBudget{}
+	for _, pod := range pods {
+		namespacePDBs, found := pdbs[pod.Namespace]
+		if !found {
+			if err := ctx.Err(); err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			list, err := client.Policy().PodDisruptionBudgets(pod.Namespace).List(apiv1.ListOptions{})
+			if err != nil {
+				return []*apiv1.Pod{}, fmt.Errorf("failed to list pod disruption budgets in %s: %v", pod.Namespace, err)
+			}
+			for i := range list.Items {
+				if allowsNoDisruptions(&list.Items[i]) {
+					namespacePDBs = append(namespacePDBs, &list.Items[i])
+				}
+			}
+			pdbs[pod.Namespace] = namespacePDBs
+		}
+		if covering := GetPodPDBs(pod, namespacePDBs); len(covering) > 0 {
+			glog.V(1).Infof("Pod %s/%s is locked by pod disruption budget %s", pod.Namespace, pod.Name, covering[0].Name)
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+// allowsNoDisruptions checks whether the effective maxUnavailable of pdb is 0. Budgets whose status
+// doesn't report the expected pods yet are only considered if they require 100% availability.
+func allowsNoDisruptions(pdb *policyv1beta1.PodDisruptionBudget) bool {
+	expected := int(pdb.Status.ExpectedPods)
+	if expected == 0 {
+		return pdb.Spec.MinAvailable.Type == intstr.String && pdb.Spec.MinAvailable.StrVal == "100%"
+	}
+	minAvailable, err := intstr.GetValueFromIntOrPercent(&pdb.Spec.MinAvailable, expected, true)
+	if err != nil {
+		glog.Warningf("Invalid minAvailable in pod disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+		return false
+	}
+	return minAvailable >= expected
+}
+
+func isBlockedByPDB(pod *apiv1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget) bool {
+	for _, pdb := range GetPodPDBs(pod, pdbs) {
+		if pdb.Status.PodDisruptionsAllowed < 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func pdbMatchesPod(pdb *policyv1beta1.PodDisruptionBudget, pod *apiv1.Pod) bool {
+	if pdb.Namespace != pod.Namespace || pdb.Spec.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		glog.Warningf("Invalid selector in pod disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+		return false
+	}
+	return !selector.Empty() && selector.Matches(labels.Set(pod.Labels))
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	replicaSetCreatedBy = "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"ReplicaSet\"}}"
+	daemonSetCreatedBy  = "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\",\"reference\":{\"kind\":\"DaemonSet\"}}"
+)
+
+func buildReplicatedPod(name string, podLabels map[string]string) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Labels:      podLabels,
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: replicaSetCreatedBy},
+		},
+		Spec: apiv1.PodSpec{NodeName: "node"},
+	}
+}
+
+func buildTestPDB(name string, podLabels map[string]string, disruptionsAllowed int32) *policyv1beta1.PodDisruptionBudget {
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+		},
+		Status: policyv1beta1.PodDisruptionBudgetStatus{PodDisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+func TestComputeNodeDisruptionScore(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+
+	free := buildReplicatedPod("free", map[string]string{"app": "free"})
+	blocked := buildReplicatedPod("blocked", map[string]string{"app": "blocked"})
+	naked := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "naked", Namespace: "default"},
+		Spec:       apiv1.PodSpec{NodeName: "node"},
+	}
+	ds := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "ds",
+			Namespace:   "default",
+			Annotations: map[string]string{apiv1.CreatedByAnnotation: daemonSetCreatedBy},
+		},
+		Spec: apiv1.PodSpec{NodeName: "node"},
+	}
+	pdbs := []*policyv1beta1.PodDisruptionBudget{
+		buildTestPDB("free", map[string]string{"app": "free"}, 1),
+		buildTestPDB("blocked", map[string]string{"app": "blocked"}, 0),
+	}
+
+	assert.Equal(t, 0.0, ComputeNodeDisruptionScore(node, []*apiv1.Pod{}, pdbs))
+	assert.Equal(t, 0.0, ComputeNodeDisruptionScore(node, []*apiv1.Pod{free, ds}, pdbs))
+	assert.Equal(t, 0.5, ComputeNodeDisruptionScore(node, []*apiv1.Pod{free, blocked}, pdbs))
+	assert.Equal(t, 1.0, ComputeNodeDisruptionScore(node, []*apiv1.Pod{blocked, naked, ds}, pdbs))
+}
+
+func TestComputePDBBlastRadius(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+	appLabels := map[string]string{"app": "web"}
+
+	var allPods []*apiv1.Pod
+	for _, name := range []string{"web1", "web2", "web3", "web4"} {
+		pod := buildReplicatedPod(name, appLabels)
+		pod.Status.Conditions = []apiv1.PodCondition{{Type: apiv1.PodReady, Status: apiv1.ConditionTrue}}
+		allPods = append(allPods, pod)
+	}
+	allPods[2].Spec.NodeName = "other"
+	allPods[3].Spec.NodeName = "other"
+	other := buildReplicatedPod("other", map[string]string{"app": "other"})
+	nodePods := []*apiv1.Pod{allPods[0], allPods[1], other}
+
+	pdb := buildTestPDB("web", appLabels, 0)
+	pdb.Spec.MinAvailable = intstr.FromString("50%")
+	current, allowed := ComputePDBBlastRadius(node, nodePods, append(allPods, other), pdb)
+	assert.Equal(t, 2, current)
+	assert.Equal(t, 2, allowed)
+
+	pdb.Spec.MinAvailable = intstr.FromInt(3)
+	current, allowed = ComputePDBBlastRadius(node, nodePods, append(allPods, other), pdb)
+	assert.Equal(t, 2, current)
+	assert.Equal(t, 1, allowed)
+
+	allPods[3].Status.Conditions = nil
+	_, allowed = ComputePDBBlastRadius(node, nodePods, allPods, pdb)
+	assert.Equal(t, 0, allowed)
+}
+
+func TestGetZeroUnavailablePDBPods(t *testing.T) {
+	withMinAvailable := func(pdb *policyv1beta1.PodDisruptionBudget, minAvailable intstr.IntOrString,
+		expected int32) *policyv1beta1.PodDisruptionBudget {
+		pdb.Spec.MinAvailable = minAvailable
+		pdb.Status.ExpectedPods = expected
+		return pdb
+	}
+	full := withMinAvailable(buildTestPDB("full", map[string]string{"app": "full"}, 0), intstr.FromString("100%"), 0)
+	exact := withMinAvailable(buildTestPDB("exact", map[string]string{"app": "exact"}, 0), intstr.FromInt(3), 3)
+	// Allows no disruptions right now, but only because a pod is not ready.
+	temporary := withMinAvailable(buildTestPDB("temporary", map[string]string{"app": "temporary"}, 0),
+		intstr.FromInt(2), 3)
+	fakeClient := fake.NewSimpleClientset(full, exact, temporary)
+
+	fullPod := buildReplicatedPod("full", map[string]string{"app": "full"})
+	exactPod := buildReplicatedPod("exact", map[string]string{"app": "exact"})
+	temporaryPod := buildReplicatedPod("temporary", map[string]string{"app": "temporary"})
+	otherNamespace := buildReplicatedPod("other", map[string]string{"app": "full"})
+	otherNamespace.Namespace = "other"
+
+	pods, err := GetZeroUnavailablePDBPods(context.Background(), fakeClient,
+		[]*apiv1.Pod{fullPod, exactPod, temporaryPod, otherNamespace})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{fullPod, exactPod}, pods)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	authorizationv1beta1 "k8s.io/kubernetes/pkg/apis/authorization/v1beta1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// ErrEvictionForbidden is returned by CheckEvictionPermission when the service account may not evict the pod.
+var ErrEvictionForbidden = fmt.Errorf("eviction forbidden")
+
+// CheckEvictionPermission asks the API server, with a SubjectAccessReview, whether the service account
+// (given as "<namespace>:<name>") may create the eviction subresource of the pod. RBAC rules, e.g. ones
+// bound to a PodSecurityPolicy, may forbid that for some pods even if the autoscaler can evict others.
+func CheckEvictionPermission(ctx context.Context, client client.Interface, pod *apiv1.Pod,
+	evictorServiceAccount string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	review, err := client.Authorization().SubjectAccessReviews().Create(&authorizationv1beta1.SubjectAccessReview{
+		Spec: authorizationv1beta1.SubjectAccessReviewSpec{
+			User: "system:serviceaccount:" + evictorServiceAccount,
+			ResourceAttributes: &authorizationv1beta1.ResourceAttributes{
+				Namespace:   pod.Namespace,
+				Name:        pod.Name,
+				Verb:        "create",
+				Group:       "policy",
+				Resource:    "pods",
+				Subresource: "eviction",
+			},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to review eviction permission for %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	if !review.Status.Allowed {
+		return false, ErrEvictionForbidden
+	}
+	return true, nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	authorizationv1beta1 "k8s.io/kubernetes/pkg/apis/authorization/v1beta1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+	"k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckEvictionPermission(t *testing.T) {
+	fakeClient := &fake.Clientset{}
+	fakeClient.Fake.AddReactor("create", "subjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+		review := action.(core.CreateAction).GetObject().(*authorizationv1beta1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "system:serviceaccount:kube-system:cluster-autoscaler" &&
+			attributes.Subresource == "eviction" && attributes.Namespace != "restricted"
+		return true, review, nil
+	})
+
+	allowed, err := CheckEvictionPermission(context.Background(), fakeClient,
+		&apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "default"}}, "kube-system:cluster-autoscaler")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = CheckEvictionPermission(context.Background(), fakeClient,
+		&apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "restricted"}}, "kube-system:cluster-autoscaler")
+	assert.Equal(t, ErrEvictionForbidden, err)
+	assert.False(t, allowed)
+}
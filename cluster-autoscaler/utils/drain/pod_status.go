@@ -0,0 +1,142 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"math"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+const (
+	// CrashLoopBackOffReason is the waiting reason reported by the kubelet for a container that keeps crashing.
+	CrashLoopBackOffReason = "CrashLoopBackOff"
+)
+
+// FilterPendingPods splits pods into the ones in the Pending phase and the rest. Pending pods bound to
+// the node hold its resources but have no running state to lose, so they can be deleted right away.
+func FilterPendingPods(pods []*apiv1.Pod) (pending, running []*apiv1.Pod) {
+	pending = []*apiv1.Pod{}
+	running = []*apiv1.Pod{}
+	for _, pod := range pods {
+		if pod.Status.Phase == apiv1.PodPending {
+			pending = append(pending, pod)
+		} else {
+			running = append(running, pod)
+		}
+	}
+	return pending, running
+}
+
+// GetCrashLoopingPods returns pods with at least one container waiting in CrashLoopBackOff. Such pods
+// are already failing, so evicting them is relatively safe.
+func GetCrashLoopingPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if isCrashLooping(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func isCrashLooping(pod *apiv1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == CrashLoopBackOffReason {
+			return true
+		}
+	}
+	return false
+}
+
+// GetExpiringPods returns pods whose ActiveDeadlineSeconds runs out within horizon. Such pods will be
+// killed by the kubelet anyway, so they shouldn't block the drain.
+func GetExpiringPods(pods []*apiv1.Pod, horizon time.Duration) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	limit := time.Now().Add(horizon)
+	for _, pod := range pods {
+		if pod.Spec.ActiveDeadlineSeconds == nil || pod.Status.StartTime == nil {
+			continue
+		}
+		deadline := pod.Status.StartTime.Add(time.Duration(*pod.Spec.ActiveDeadlineSeconds) * time.Second)
+		if !deadline.After(limit) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// GetRecentlyRestartedPods returns pods with a container that restarted within the last window, based
+// on the finish time of its previous run. Such pods may still be recovering and evicting them would
+// double the disruption.
+func GetRecentlyRestartedPods(pods []*apiv1.Pod, window time.Duration) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	since := time.Now().Add(-window)
+	for _, pod := range pods {
+		if restartedSince(pod, since) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func restartedSince(pod *apiv1.Pod, since time.Time) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.RestartCount == 0 || status.LastTerminationState.Terminated == nil {
+			continue
+		}
+		if status.LastTerminationState.Terminated.FinishedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPinnedPods returns pods placed on their node by setting NodeName directly instead of going through
+// the scheduler, i.e. pods without the PodScheduled condition. Their controllers may put them back on
+// the same node, so draining may require a label or taint change. DaemonSet and mirror pods, which
+// always bypass the scheduler, are not reported.
+func GetPinnedPods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if IsMirrorPod(pod) {
+			continue
+		}
+		if refKind, err := CreatorRefKind(pod); err == nil && refKind == "DaemonSet" {
+			continue
+		}
+		if isPinned(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func isPinned(pod *apiv1.Pod) bool {
+	return pod.Spec.NodeName != "" && !podConditionIsTrue(pod, apiv1.PodScheduled)
+}
+
+// EstimateDrainRestarts estimates how many container restarts draining the pods will cause, for SLO
+// reporting. avgCrashRate is the average number of crashes per second of a starting pod, so each
+// rescheduled pod is expected to restart avgCrashRate * startupTime times. The result is rounded.
+func EstimateDrainRestarts(pods []*apiv1.Pod, avgCrashRate float64, startupTime time.Duration) int {
+	if avgCrashRate <= 0 || startupTime <= 0 {
+		return 0
+	}
+	return int(math.Floor(float64(len(pods))*avgCrashRate*startupTime.Seconds() + 0.5))
+}
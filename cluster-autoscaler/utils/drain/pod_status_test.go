@@ -0,0 +1,141 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterPendingPods(t *testing.T) {
+	buildPod := func(name string, phase apiv1.PodPhase) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     apiv1.PodStatus{Phase: phase},
+		}
+	}
+	pending := buildPod("pending", apiv1.PodPending)
+	running := buildPod("running", apiv1.PodRunning)
+	unknown := buildPod("unknown", "")
+
+	pendingPods, runningPods := FilterPendingPods([]*apiv1.Pod{running, pending, unknown})
+	assert.Equal(t, []*apiv1.Pod{pending}, pendingPods)
+	assert.Equal(t, []*apiv1.Pod{running, unknown}, runningPods)
+
+	pendingPods, runningPods = FilterPendingPods(nil)
+	assert.Empty(t, pendingPods)
+	assert.Empty(t, runningPods)
+}
+
+func TestGetCrashLoopingPods(t *testing.T) {
+	crashing := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "crashing", Namespace: "default"},
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: "ok", State: apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}}},
+				{Name: "bad", State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: CrashLoopBackOffReason}}},
+			},
+		},
+	}
+	pulling := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "pulling", Namespace: "default"},
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: "c", State: apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+			},
+		},
+	}
+	running := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "running", Namespace: "default"},
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: "c", State: apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}}},
+			},
+		},
+	}
+
+	result := GetCrashLoopingPods([]*apiv1.Pod{crashing, pulling, running})
+	assert.Equal(t, []*apiv1.Pod{crashing}, result)
+}
+
+func TestGetExpiringPods(t *testing.T) {
+	buildPod := func(name string, started time.Time, deadlineSeconds *int64) *apiv1.Pod {
+		startTime := metav1.NewTime(started)
+		return &apiv1.Pod{
+			ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       apiv1.PodSpec{ActiveDeadlineSeconds: deadlineSeconds},
+			Status:     apiv1.PodStatus{StartTime: &startTime},
+		}
+	}
+	hour := int64(3600)
+	now := time.Now()
+
+	expiring := buildPod("expiring", now.Add(-55*time.Minute), &hour)
+	longRunning := buildPod("long-running", now.Add(-10*time.Minute), &hour)
+	noDeadline := buildPod("no-deadline", now.Add(-55*time.Minute), nil)
+
+	result := GetExpiringPods([]*apiv1.Pod{expiring, longRunning, noDeadline}, 10*time.Minute)
+	assert.Equal(t, []*apiv1.Pod{expiring}, result)
+}
+
+func buildRestartedPod(name string, restarts int32, finishedAt time.Time) *apiv1.Pod {
+	pod := buildReplicatedPod(name, nil)
+	pod.Status.ContainerStatuses = []apiv1.ContainerStatus{{
+		RestartCount: restarts,
+		LastTerminationState: apiv1.ContainerState{
+			Terminated: &apiv1.ContainerStateTerminated{FinishedAt: metav1.NewTime(finishedAt)},
+		},
+	}}
+	return pod
+}
+
+func TestGetRecentlyRestartedPods(t *testing.T) {
+	now := time.Now()
+	recent := buildRestartedPod("recent", 2, now.Add(-time.Minute))
+	old := buildRestartedPod("old", 2, now.Add(-time.Hour))
+	neverRestarted := buildReplicatedPod("never", nil)
+
+	result := GetRecentlyRestartedPods([]*apiv1.Pod{recent, old, neverRestarted}, 10*time.Minute)
+	assert.Equal(t, []*apiv1.Pod{recent}, result)
+}
+
+func TestGetPinnedPods(t *testing.T) {
+	scheduled := buildReplicatedPod("scheduled", nil)
+	scheduled.Status.Conditions = []apiv1.PodCondition{{Type: apiv1.PodScheduled, Status: apiv1.ConditionTrue}}
+	pinned := buildReplicatedPod("pinned", nil)
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	pending := buildReplicatedPod("pending", nil)
+	pending.Spec.NodeName = ""
+
+	result := GetPinnedPods([]*apiv1.Pod{scheduled, pinned, dsPod, pending})
+	assert.Equal(t, []*apiv1.Pod{pinned}, result)
+}
+
+func TestEstimateDrainRestarts(t *testing.T) {
+	pods := make([]*apiv1.Pod, 10)
+
+	assert.Equal(t, 5, EstimateDrainRestarts(pods, 0.01, 50*time.Second))
+	assert.Equal(t, 1, EstimateDrainRestarts(pods, 0.001, 60*time.Second))
+	assert.Equal(t, 0, EstimateDrainRestarts(pods, 0, time.Minute))
+	assert.Equal(t, 0, EstimateDrainRestarts(nil, 0.5, time.Minute))
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	api "k8s.io/kubernetes/pkg/api"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// Names of the checks run by RunDrainPreflightChecks.
+const (
+	APIServerPreflightCheck = "APIServer"
+	KubeletPreflightCheck   = "Kubelet"
+	CordonPreflightCheck    = "Cordon"
+	PodsPreflightCheck      = "Pods"
+	PDBPreflightCheck       = "PodDisruptionBudget"
+)
+
+// PreflightWarning is a problem found before a drain that doesn't prevent it.
+type PreflightWarning struct {
+	Check   string
+	Message string
+}
+
+// PreflightError is a problem found before a drain that would make it fail.
+type PreflightError struct {
+	Check string
+	Err   error
+}
+
+// Error implements error.
+func (e PreflightError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Check, e.Err)
+}
+
+// RunDrainPreflightChecks checks, without changing anything, whether the node can be drained with opts:
+// the API server responds, the kubelet of the node reports status, the node isn't cordoned already,
+// its pods pass the checks of GetPodsForDeletionOnNodeDrainWithOptions and no PodDisruptionBudget
+// blocks their eviction. Problems that would make the drain fail are returned as errors, the others
+// as warnings.
+func RunDrainPreflightChecks(ctx context.Context, client client.Interface, nodeName string,
+	opts DrainOptions) ([]PreflightWarning, []PreflightError) {
+	warnings := []PreflightWarning{}
+	errs := []PreflightError{}
+	fail := func(check string, err error) ([]PreflightWarning, []PreflightError) {
+		return warnings, append(errs, PreflightError{Check: check, Err: err})
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(APIServerPreflightCheck, err)
+	}
+	if _, err := client.Discovery().ServerVersion(); err != nil {
+		return fail(APIServerPreflightCheck, fmt.Errorf("API server not ready: %v", err))
+	}
+
+	node, err := client.Core().Nodes().Get(nodeName)
+	if err != nil {
+		return fail(KubeletPreflightCheck, fmt.Errorf("failed to get node %s: %v", nodeName, err))
+	}
+	if status := nodeReadyStatus(node); status == apiv1.ConditionUnknown {
+		errs = append(errs, PreflightError{Check: KubeletPreflightCheck,
+			Err: fmt.Errorf("kubelet on %s is not reporting status, pods won't terminate gracefully", nodeName)})
+	} else if status != apiv1.ConditionTrue {
+		warnings = append(warnings, PreflightWarning{Check: KubeletPreflightCheck,
+			Message: fmt.Sprintf("node %s is not ready", nodeName)})
+	}
+	if node.Spec.Unschedulable {
+		warnings = append(warnings, PreflightWarning{Check: CordonPreflightCheck,
+			Message: fmt.Sprintf("node %s is already cordoned", nodeName)})
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(PodsPreflightCheck, err)
+	}
+	allPods, err := listPodsOnNode(client, nodeName)
+	if err != nil {
+		return fail(PodsPreflightCheck, err)
+	}
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions(allPods, api.Codecs.UniversalDecoder(), client, opts)
+	if err != nil {
+		return fail(PodsPreflightCheck, err)
+	}
+
+	pdbList, err := client.Policy().PodDisruptionBudgets(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return fail(PDBPreflightCheck, fmt.Errorf("failed to list pod disruption budgets: %v", err))
+	}
+	pdbs := make([]*policyv1beta1.PodDisruptionBudget, 0, len(pdbList.Items))
+	for i := range pdbList.Items {
+		pdbs = append(pdbs, &pdbList.Items[i])
+	}
+	for _, pod := range pods {
+		if isBlockedByPDB(pod, pdbs) {
+			warnings = append(warnings, PreflightWarning{Check: PDBPreflightCheck,
+				Message: fmt.Sprintf("eviction of %s/%s is currently not allowed by its disruption budget",
+					pod.Namespace, pod.Name)})
+		}
+	}
+	return warnings, errs
+}
+
+func nodeReadyStatus(node *apiv1.Node) apiv1.ConditionStatus {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == apiv1.NodeReady {
+			return condition.Status
+		}
+	}
+	return apiv1.ConditionUnknown
+}
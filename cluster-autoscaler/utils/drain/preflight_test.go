@@ -0,0 +1,67 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunDrainPreflightChecks(t *testing.T) {
+	buildNode := func(ready apiv1.ConditionStatus, unschedulable bool) *apiv1.Node {
+		return &apiv1.Node{
+			ObjectMeta: apiv1.ObjectMeta{Name: "node"},
+			Spec:       apiv1.NodeSpec{Unschedulable: unschedulable},
+			Status: apiv1.NodeStatus{
+				Conditions: []apiv1.NodeCondition{{Type: apiv1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	rsPod := buildReplicatedPod("rs", map[string]string{"app": "web"})
+
+	warnings, errs := RunDrainPreflightChecks(context.Background(),
+		fake.NewSimpleClientset(buildNode(apiv1.ConditionTrue, false), rsPod), "node", DrainOptions{})
+	assert.Empty(t, warnings)
+	assert.Empty(t, errs)
+
+	warnings, errs = RunDrainPreflightChecks(context.Background(),
+		fake.NewSimpleClientset(buildNode(apiv1.ConditionTrue, true), rsPod, buildTestPDB("web", map[string]string{"app": "web"}, 0)),
+		"node", DrainOptions{})
+	assert.Empty(t, errs)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, CordonPreflightCheck, warnings[0].Check)
+		assert.Equal(t, PDBPreflightCheck, warnings[1].Check)
+	}
+
+	nakedPod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "naked", Namespace: "default"}, Spec: apiv1.PodSpec{NodeName: "node"}}
+	_, errs = RunDrainPreflightChecks(context.Background(),
+		fake.NewSimpleClientset(buildNode(apiv1.ConditionUnknown, false), nakedPod), "node", DrainOptions{})
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, KubeletPreflightCheck, errs[0].Check)
+		assert.Equal(t, PodsPreflightCheck, errs[1].Check)
+	}
+
+	_, errs = RunDrainPreflightChecks(context.Background(), fake.NewSimpleClientset(), "node", DrainOptions{})
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, KubeletPreflightCheck, errs[0].Check)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	api "k8s.io/kubernetes/pkg/api"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+const (
+	// DryRunAnnotation marks events produced by PreviewDrainAsEvents.
+	DryRunAnnotation = "cluster-autoscaler.kubernetes.io/dry-run"
+
+	previewComponent = "cluster-autoscaler"
+)
+
+// PreviewDrainAsEvents builds events describing what draining the node with the given options would do:
+// the cordon of the node and, for every pod, whether it would be evicted, skipped (DaemonSet and mirror
+// pods) or would block the drain. If the drain would be blocked, no pod is reported as evicted. Nothing is
+// modified in the cluster. The events carry DryRunAnnotation and can be created by the caller to preview
+// the drain.
+func PreviewDrainAsEvents(ctx context.Context, client client.Interface, nodeName string, opts DrainOptions) ([]apiv1.Event, error) {
+	node, err := client.Core().Nodes().Get(nodeName)
+	if err != nil {
+		return []apiv1.Event{}, fmt.Errorf("failed to get node %s: %v", nodeName, err)
+	}
+	pods, err := listPodsOnNode(client, nodeName)
+	if err != nil {
+		return []apiv1.Event{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return []apiv1.Event{}, err
+	}
+	blockers, podsToDelete, err := previewPodDecisions(ctx, client, pods, opts)
+	if err != nil {
+		return []apiv1.Event{}, err
+	}
+
+	now := metav1.Now()
+	events := []apiv1.Event{
+		buildPreviewEvent(apiv1.ObjectReference{Kind: "Node", Name: node.Name, UID: node.UID}, apiv1.NamespaceDefault,
+			apiv1.EventTypeNormal, "DrainPreviewCordon", "node would be marked as unschedulable", now),
+	}
+	for _, pod := range pods {
+		ref := apiv1.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID}
+		var event apiv1.Event
+		if blocker, found := blockers[pod]; found {
+			event = buildPreviewEvent(ref, pod.Namespace, apiv1.EventTypeWarning, "DrainPreviewBlocked",
+				fmt.Sprintf("pod would block the drain: %v", blocker), now)
+		} else if podsToDelete[pod] {
+			event = buildPreviewEvent(ref, pod.Namespace, apiv1.EventTypeNormal, "DrainPreviewEvict",
+				"pod would be evicted", now)
+		} else {
+			event = buildPreviewEvent(ref, pod.Namespace, apiv1.EventTypeNormal, "DrainPreviewSkip",
+				"pod would be left on the node", now)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// previewPodDecisions runs the drain logic on all pods at once, as the drain would. If it fails, the
+// error is attributed to the pods that fail on their own. If none does, the pods fail only together
+// (e.g. because of a quorum policy) and the error is attributed to all pods that would be deleted.
+func previewPodDecisions(ctx context.Context, client client.Interface, pods []*apiv1.Pod,
+	opts DrainOptions) (blockers map[*apiv1.Pod]error, podsToDelete map[*apiv1.Pod]bool, err error) {
+	decoder := api.Codecs.UniversalDecoder()
+	blockers = make(map[*apiv1.Pod]error)
+	podsToDelete = make(map[*apiv1.Pod]bool)
+	deleted, drainErr := GetPodsForDeletionOnNodeDrainWithOptions(pods, decoder, client, opts)
+	if drainErr == nil {
+		for _, pod := range deleted {
+			podsToDelete[pod] = true
+		}
+		return blockers, podsToDelete, nil
+	}
+
+	for _, pod := range pods {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		deleted, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{pod}, decoder, client, opts)
+		if err != nil {
+			blockers[pod] = err
+		} else if len(deleted) > 0 {
+			podsToDelete[pod] = true
+		}
+	}
+	if len(blockers) == 0 {
+		for pod := range podsToDelete {
+			blockers[pod] = drainErr
+		}
+	}
+	// The drain is rejected, so nothing would be evicted.
+	return blockers, map[*apiv1.Pod]bool{}, nil
+}
+
+func buildPreviewEvent(ref apiv1.ObjectReference, namespace, eventType, reason, message string, now metav1.Time) apiv1.Event {
+	return apiv1.Event{
+		ObjectMeta: apiv1.ObjectMeta{
+			GenerateName: ref.Name + "-",
+			Namespace:    namespace,
+			Annotations:  map[string]string{DryRunAnnotation: "true"},
+		},
+		InvolvedObject: ref,
+		Reason:         reason,
+		Message:        message,
+		Source:         apiv1.EventSource{Component: previewComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           eventType,
+	}
+}
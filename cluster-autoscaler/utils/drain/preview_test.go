@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewDrainAsEvents(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+	rsPod := buildReplicatedPod("rs", nil)
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	nakedPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "naked", Namespace: "default"},
+		Spec:       apiv1.PodSpec{NodeName: "node"},
+	}
+	fakeClient := fake.NewSimpleClientset(node, rsPod, dsPod, nakedPod)
+
+	events, err := PreviewDrainAsEvents(context.Background(), fakeClient, "node", DrainOptions{})
+	assert.NoError(t, err)
+	reasons := make(map[string]string)
+	for _, event := range events {
+		assert.Equal(t, "true", event.Annotations[DryRunAnnotation])
+		reasons[event.InvolvedObject.Name] = event.Reason
+	}
+	// The naked pod rejects the drain, so nothing would be evicted.
+	assert.Equal(t, map[string]string{
+		"node":  "DrainPreviewCordon",
+		"rs":    "DrainPreviewSkip",
+		"ds":    "DrainPreviewSkip",
+		"naked": "DrainPreviewBlocked",
+	}, reasons)
+
+	// Nothing is changed in the cluster.
+	for _, action := range fakeClient.Actions() {
+		assert.Contains(t, []string{"get", "list"}, action.GetVerb())
+	}
+
+	_, err = PreviewDrainAsEvents(context.Background(), fakeClient, "missing", DrainOptions{})
+	assert.Error(t, err)
+
+	events, err = PreviewDrainAsEvents(context.Background(), fake.NewSimpleClientset(node, rsPod, dsPod), "node",
+		DrainOptions{})
+	assert.NoError(t, err)
+	reasons = make(map[string]string)
+	for _, event := range events {
+		reasons[event.InvolvedObject.Name] = event.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"node": "DrainPreviewCordon",
+		"rs":   "DrainPreviewEvict",
+		"ds":   "DrainPreviewSkip",
+	}, reasons)
+}
+
+func TestPreviewDrainAsEventsQuorum(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+	db0 := buildReplicatedPod("db-0", map[string]string{"app": "db"})
+	db1 := buildReplicatedPod("db-1", map[string]string{"app": "db"})
+	fakeClient := fake.NewSimpleClientset(node, db0, db1)
+
+	var opts DrainOptions
+	opts.RegisterQuorumPolicy("db", maxMembersPolicy{max: 1})
+	events, err := PreviewDrainAsEvents(context.Background(), fakeClient, "node", opts)
+	assert.NoError(t, err)
+	reasons := make(map[string]string)
+	for _, event := range events {
+		reasons[event.InvolvedObject.Name] = event.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"node": "DrainPreviewCordon",
+		"db-0": "DrainPreviewBlocked",
+		"db-1": "DrainPreviewBlocked",
+	}, reasons)
+}
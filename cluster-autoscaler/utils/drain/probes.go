@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+const (
+	// Probe defaults applied by the API server when the fields are unset.
+	defaultProbePeriodSeconds    = 10
+	defaultProbeFailureThreshold = 3
+)
+
+// LivenessRisk describes the chance that a liveness probe of a pod kills it while it is being rescheduled.
+type LivenessRisk struct {
+	// High is set if rescheduling is expected to take longer than the liveness budget.
+	High bool
+	// Budget is the shortest time after which a liveness probe of the pod may restart a container.
+	Budget time.Duration
+}
+
+// GetAggressiveReadinessProbePods returns pods with a container whose readiness probe fails after a single
+// failed check. Such pods are likely to flap between ready and not ready after being rescheduled.
+func GetAggressiveReadinessProbePods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if hasAggressiveReadinessProbe(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func hasAggressiveReadinessProbe(pod *apiv1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.ReadinessProbe != nil && container.ReadinessProbe.FailureThreshold == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPodLivenessRisk compares estimatedReschedulingTime with the time the liveness probes of the pod give
+// a container before restarting it (initial delay plus period times failure threshold). Pods without
+// liveness probes carry no risk.
+func GetPodLivenessRisk(pod *apiv1.Pod, estimatedReschedulingTime time.Duration) LivenessRisk {
+	risk := LivenessRisk{}
+	found := false
+	for _, container := range pod.Spec.Containers {
+		if container.LivenessProbe == nil {
+			continue
+		}
+		if budget := probeBudget(container.LivenessProbe); !found || budget < risk.Budget {
+			risk.Budget = budget
+			found = true
+		}
+	}
+	risk.High = found && estimatedReschedulingTime > risk.Budget
+	return risk
+}
+
+// GetSlowReadinessPods returns pods that may need more than threshold to become ready after being
+// rescheduled, based on the initial delay, period and failure threshold of their readiness probes.
+func GetSlowReadinessPods(pods []*apiv1.Pod, threshold time.Duration) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if readinessDelay(pod) > threshold {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// readinessDelay returns the longest readiness probe budget of the containers of the pod.
+func readinessDelay(pod *apiv1.Pod) time.Duration {
+	var delay time.Duration
+	for _, container := range pod.Spec.Containers {
+		if container.ReadinessProbe == nil {
+			continue
+		}
+		if budget := probeBudget(container.ReadinessProbe); budget > delay {
+			delay = budget
+		}
+	}
+	return delay
+}
+
+// probeBudget returns the time after which the probe is considered failed if it never succeeds.
+func probeBudget(probe *apiv1.Probe) time.Duration {
+	period := probe.PeriodSeconds
+	if period == 0 {
+		period = defaultProbePeriodSeconds
+	}
+	threshold := probe.FailureThreshold
+	if threshold == 0 {
+		threshold = defaultProbeFailureThreshold
+	}
+	return time.Duration(probe.InitialDelaySeconds+period*threshold) * time.Second
+}
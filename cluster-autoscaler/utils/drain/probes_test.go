@@ -0,0 +1,60 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildPodWithProbes(name string, readiness, liveness *apiv1.Probe) *apiv1.Pod {
+	pod := buildReplicatedPod(name, nil)
+	pod.Spec.Containers = []apiv1.Container{{Name: "app", ReadinessProbe: readiness, LivenessProbe: liveness}}
+	return pod
+}
+
+func TestGetAggressiveReadinessProbePods(t *testing.T) {
+	aggressive := buildPodWithProbes("aggressive", &apiv1.Probe{FailureThreshold: 1}, nil)
+	relaxed := buildPodWithProbes("relaxed", &apiv1.Probe{FailureThreshold: 3}, nil)
+	noProbe := buildPodWithProbes("no-probe", nil, nil)
+
+	result := GetAggressiveReadinessProbePods([]*apiv1.Pod{aggressive, relaxed, noProbe})
+	assert.Equal(t, []*apiv1.Pod{aggressive}, result)
+}
+
+func TestGetPodLivenessRisk(t *testing.T) {
+	strict := buildPodWithProbes("strict", nil, &apiv1.Probe{InitialDelaySeconds: 5, PeriodSeconds: 5, FailureThreshold: 1})
+	defaults := buildPodWithProbes("defaults", nil, &apiv1.Probe{InitialDelaySeconds: 30})
+	noProbe := buildPodWithProbes("no-probe", nil, nil)
+
+	assert.Equal(t, LivenessRisk{High: true, Budget: 10 * time.Second}, GetPodLivenessRisk(strict, time.Minute))
+	assert.Equal(t, LivenessRisk{High: false, Budget: time.Minute}, GetPodLivenessRisk(defaults, time.Minute))
+	assert.Equal(t, LivenessRisk{}, GetPodLivenessRisk(noProbe, time.Hour))
+}
+
+func TestGetSlowReadinessPods(t *testing.T) {
+	slow := buildPodWithProbes("slow", &apiv1.Probe{InitialDelaySeconds: 300}, nil)
+	fast := buildPodWithProbes("fast", &apiv1.Probe{InitialDelaySeconds: 5, PeriodSeconds: 5, FailureThreshold: 1}, nil)
+	noProbe := buildPodWithProbes("no-probe", nil, nil)
+
+	result := GetSlowReadinessPods([]*apiv1.Pod{slow, fast, noProbe}, time.Minute)
+	assert.Equal(t, []*apiv1.Pod{slow}, result)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"sort"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// QuorumSafeEvictionPolicy is implemented for quorum-based workloads (e.g. etcd, Cassandra or
+// Zookeeper) that must not lose more than (N-1)/2 members at once.
+type QuorumSafeEvictionPolicy interface {
+	// IsQuorumSafe checks whether the pods can be evicted together without breaking the quorum.
+	// Pods that the policy doesn't manage should be ignored.
+	IsQuorumSafe(pods []*apiv1.Pod) (bool, error)
+}
+
+// RegisterQuorumPolicy adds a policy under the given name. Unless DeleteAll is set, the drain is
+// rejected if any registered policy doesn't consider the pods to be deleted quorum-safe.
+func (o *DrainOptions) RegisterQuorumPolicy(name string, policy QuorumSafeEvictionPolicy) {
+	if o.QuorumPolicies == nil {
+		o.QuorumPolicies = make(map[string]QuorumSafeEvictionPolicy)
+	}
+	o.QuorumPolicies[name] = policy
+}
+
+// checkQuorumPolicies consults the policies, in order of their names, about the pods.
+func checkQuorumPolicies(pods []*apiv1.Pod, policies map[string]QuorumSafeEvictionPolicy) error {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		safe, err := policies[name].IsQuorumSafe(pods)
+		if err != nil {
+			return fmt.Errorf("quorum policy %s failed: %v", name, err)
+		}
+		if !safe {
+			return fmt.Errorf("evicting pods would break the quorum according to policy %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	api "k8s.io/kubernetes/pkg/api"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// maxMembersPolicy considers evicting at most max pods with the app=db label quorum-safe.
+type maxMembersPolicy struct {
+	max int
+}
+
+func (p maxMembersPolicy) IsQuorumSafe(pods []*apiv1.Pod) (bool, error) {
+	members := 0
+	for _, pod := range pods {
+		if pod.Labels["app"] == "db" {
+			members++
+		}
+	}
+	return members <= p.max, nil
+}
+
+func TestDrainQuorumPolicies(t *testing.T) {
+	pods := []*apiv1.Pod{
+		buildReplicatedPod("db-0", map[string]string{"app": "db"}),
+		buildReplicatedPod("db-1", map[string]string{"app": "db"}),
+		buildReplicatedPod("web", map[string]string{"app": "web"}),
+	}
+
+	opts := DrainOptions{}
+	opts.RegisterQuorumPolicy("db", maxMembersPolicy{max: 2})
+	result, err := GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	opts.RegisterQuorumPolicy("db", maxMembersPolicy{max: 1})
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.Error(t, err)
+
+	opts.DeleteAll = true
+	result, err = GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+}
+
+type quorumPolicyFunc func(pods []*apiv1.Pod) (bool, error)
+
+func (f quorumPolicyFunc) IsQuorumSafe(pods []*apiv1.Pod) (bool, error) {
+	return f(pods)
+}
+
+func TestDrainOptionsEqualQuorumPolicies(t *testing.T) {
+	var a, b DrainOptions
+	a.RegisterQuorumPolicy("db", maxMembersPolicy{max: 1})
+	b.RegisterQuorumPolicy("db", maxMembersPolicy{max: 1})
+	assert.True(t, DrainOptionsEqual(a, b))
+	b.RegisterQuorumPolicy("db", maxMembersPolicy{max: 2})
+	assert.False(t, DrainOptionsEqual(a, b))
+
+	policy := &maxMembersPolicy{max: 1}
+	a.RegisterQuorumPolicy("db", policy)
+	b.RegisterQuorumPolicy("db", &maxMembersPolicy{max: 1})
+	assert.False(t, DrainOptionsEqual(a, b))
+	b.RegisterQuorumPolicy("db", policy)
+	assert.True(t, DrainOptionsEqual(a, b))
+
+	allowAll := quorumPolicyFunc(func(pods []*apiv1.Pod) (bool, error) { return true, nil })
+	a.RegisterQuorumPolicy("db", allowAll)
+	b.RegisterQuorumPolicy("db", allowAll)
+	assert.True(t, DrainOptionsEqual(a, b))
+	b.RegisterQuorumPolicy("cache", allowAll)
+	assert.False(t, DrainOptionsEqual(a, b))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+// ErrCrossRegionNotAllowed is returned when the drain is blocked by a pod that could be rescheduled to
+// another region while CrossRegionDrainPolicy forbids that.
+var ErrCrossRegionNotAllowed = fmt.Errorf("pod that may be moved to another region present")
+
+// CrossRegionDrainPolicy controls whether drained pods may move to another region.
+type CrossRegionDrainPolicy struct {
+	// AllowCrossRegion disables the check.
+	AllowCrossRegion bool
+	// RegionLabel is the node label holding the region. Empty means metav1.LabelZoneRegion.
+	RegionLabel string
+	// Node is the drained node. Nothing is blocked if it has no region label.
+	Node *apiv1.Node
+	// Nodes are the nodes the pods may be rescheduled to. Nodes without the region label are ignored.
+	Nodes []*apiv1.Node
+}
+
+// mayLeaveRegion checks whether the pod may be scheduled on one of policy.Nodes that is in a different
+// region than policy.Node. Only the node selector and the required node affinity of the pod are taken
+// into account.
+func mayLeaveRegion(pod *apiv1.Pod, policy *CrossRegionDrainPolicy) (bool, error) {
+	regionLabel := policy.RegionLabel
+	if regionLabel == "" {
+		regionLabel = metav1.LabelZoneRegion
+	}
+	if policy.Node == nil || policy.Node.Labels[regionLabel] == "" {
+		return false, nil
+	}
+	region := policy.Node.Labels[regionLabel]
+	affinity, err := apiv1.GetAffinityFromPodAnnotations(pod.Annotations)
+	if err != nil {
+		return false, fmt.Errorf("failed to get affinity of %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	for _, node := range policy.Nodes {
+		nodeRegion := node.Labels[regionLabel]
+		if nodeRegion == "" || nodeRegion == region {
+			continue
+		}
+		matches, err := podMatchesNodeLabels(pod, affinity, node)
+		if err != nil {
+			return false, fmt.Errorf("failed to match %s/%s against node %s: %v", pod.Namespace, pod.Name, node.Name, err)
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// podMatchesNodeLabels checks whether the node selector and the required node affinity of the pod
+// allow scheduling it on the node.
+func podMatchesNodeLabels(pod *apiv1.Pod, affinity *apiv1.Affinity, node *apiv1.Node) (bool, error) {
+	if !labels.SelectorFromSet(labels.Set(pod.Spec.NodeSelector)).Matches(labels.Set(node.Labels)) {
+		return false, nil
+	}
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+	// Terms are ORed.
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		selector, err := apiv1.NodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil {
+			return false, err
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
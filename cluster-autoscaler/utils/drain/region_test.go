@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	api "k8s.io/kubernetes/pkg/api"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildRegionNode(name, regionLabel, region string) *apiv1.Node {
+	return &apiv1.Node{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{regionLabel: region, "disk": "hdd"},
+		},
+	}
+}
+
+func TestDrainCrossRegionPolicy(t *testing.T) {
+	const affinityPrefix = `{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":[`
+	withAffinity := func(name, terms string) *apiv1.Pod {
+		pod := buildReplicatedPod(name, nil)
+		pod.Annotations[apiv1.AffinityAnnotationKey] = affinityPrefix + terms + `]}}}`
+		return pod
+	}
+	selector := buildReplicatedPod("selector", nil)
+	selector.Spec.NodeSelector = map[string]string{metav1.LabelZoneRegion: "us-east1"}
+	pinned := withAffinity("pinned",
+		`{"matchExpressions":[{"key":"`+metav1.LabelZoneRegion+`","operator":"In","values":["us-east1"]}]}`)
+	ssdOnly := withAffinity("ssd-only",
+		`{"matchExpressions":[{"key":"disk","operator":"In","values":["ssd"]}]}`)
+	twoRegions := withAffinity("two-regions",
+		`{"matchExpressions":[{"key":"`+metav1.LabelZoneRegion+`","operator":"In","values":["us-east1","us-west1"]}]}`)
+	unpinnedTerm := withAffinity("unpinned-term",
+		`{"matchExpressions":[{"key":"`+metav1.LabelZoneRegion+`","operator":"In","values":["us-east1"]}]},`+
+			`{"matchExpressions":[{"key":"disk","operator":"In","values":["hdd"]}]}`)
+	free := buildReplicatedPod("free", nil)
+
+	drained := buildRegionNode("drained", metav1.LabelZoneRegion, "us-east1")
+	east := buildRegionNode("east", metav1.LabelZoneRegion, "us-east1")
+	west := buildRegionNode("west", metav1.LabelZoneRegion, "us-west1")
+	unlabeled := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "unlabeled"}}
+
+	opts := DrainOptions{CrossRegionPolicy: &CrossRegionDrainPolicy{Node: drained, Nodes: []*apiv1.Node{east, west}}}
+	for _, pod := range []*apiv1.Pod{selector, pinned, ssdOnly} {
+		_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{pod}, api.Codecs.UniversalDecoder(), nil, opts)
+		assert.NoError(t, err, pod.Name)
+	}
+	for _, pod := range []*apiv1.Pod{twoRegions, unpinnedTerm, free} {
+		_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{pod}, api.Codecs.UniversalDecoder(), nil, opts)
+		assert.Equal(t, ErrCrossRegionNotAllowed, err, pod.Name)
+	}
+
+	// Without nodes in other regions there is nowhere else to go.
+	opts.CrossRegionPolicy.Nodes = []*apiv1.Node{east, unlabeled}
+	_, err := GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{free}, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.NoError(t, err)
+
+	opts.CrossRegionPolicy = &CrossRegionDrainPolicy{Node: unlabeled, Nodes: []*apiv1.Node{east, west}}
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{free}, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.NoError(t, err)
+
+	opts.CrossRegionPolicy = &CrossRegionDrainPolicy{AllowCrossRegion: true, Node: drained, Nodes: []*apiv1.Node{west}}
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{free}, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.NoError(t, err)
+
+	custom := buildReplicatedPod("custom", nil)
+	custom.Spec.NodeSelector = map[string]string{"example.com/region": "eu"}
+	opts.CrossRegionPolicy = &CrossRegionDrainPolicy{
+		RegionLabel: "example.com/region",
+		Node:        buildRegionNode("drained", "example.com/region", "eu"),
+		Nodes:       []*apiv1.Node{buildRegionNode("us", "example.com/region", "us")},
+	}
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{custom}, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.NoError(t, err)
+	_, err = GetPodsForDeletionOnNodeDrainWithOptions([]*apiv1.Pod{free}, api.Codecs.UniversalDecoder(), nil, opts)
+	assert.Equal(t, ErrCrossRegionNotAllowed, err)
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+// revisionAnnotation holds the revision of a deployment and of each of its replica sets.
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// rolloutCheckInterval is how often deployments are checked while waiting for rolling updates.
+var rolloutCheckInterval = 5 * time.Second
+
+// GetPodsForDrainRespectingRollingUpdate works like GetPodsForDeletionOnNodeDrainWithOptions for the pods
+// on node, but first waits up to rolloutTimeout for rolling updates that have no room for another
+// disruption: deployments being updated that already run maxSurge extra replicas or miss maxUnavailable
+// available ones. If both maxSurge and maxUnavailable are 0, maxUnavailable is treated as 1. An error is
+// returned if such rollouts are still in progress after the timeout.
+func GetPodsForDrainRespectingRollingUpdate(ctx context.Context, client client.Interface, node string,
+	maxSurge, maxUnavailable int, rolloutTimeout time.Duration, opts DrainOptions) ([]*apiv1.Pod, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, rolloutTimeout)
+	defer cancel()
+	if err := waitForRollingUpdates(ctx, client, int32(maxSurge), int32(maxUnavailable)); err != nil {
+		return []*apiv1.Pod{}, err
+	}
+
+	pods, err := listPodsOnNode(client, node)
+	if err != nil {
+		return []*apiv1.Pod{}, err
+	}
+	return GetPodsForDeletionOnNodeDrainWithOptions(pods, api.Codecs.UniversalDecoder(), client, opts)
+}
+
+// waitForRollingUpdates polls deployments until none of them is in a rolling update without room for
+// another disruption.
+func waitForRollingUpdates(ctx context.Context, client client.Interface, maxSurge, maxUnavailable int32) error {
+	for {
+		deploymentList, err := client.Extensions().Deployments(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list deployments: %v", err)
+		}
+		blocking := []string{}
+		for i := range deploymentList.Items {
+			deployment := &deploymentList.Items[i]
+			if isRolloutAtLimit(deployment, maxSurge, maxUnavailable) {
+				blocking = append(blocking, deployment.Namespace+"/"+deployment.Name)
+			}
+		}
+		if len(blocking) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rolling updates of %s still in progress: %v", strings.Join(blocking, ","), ctx.Err())
+		case <-time.After(rolloutCheckInterval):
+		}
+	}
+}
+
+// isRolloutAtLimit checks whether the deployment is being updated and runs maxSurge extra or misses
+// maxUnavailable available replicas. A maxSurge of 0 allows no extra replicas, so only availability is
+// checked then. If both are 0, maxUnavailable is 1, as in the deployment controller.
+func isRolloutAtLimit(deployment *extensions.Deployment, maxSurge, maxUnavailable int32) bool {
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxUnavailable = 1
+	}
+	if deployment.Status.UpdatedReplicas >= deployment.Status.Replicas {
+		return false
+	}
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	surge := deployment.Status.Replicas - desired
+	unavailable := desired - deployment.Status.AvailableReplicas
+	return (maxSurge > 0 && surge >= maxSurge) || unavailable >= maxUnavailable
+}
+
+// GetDeploymentRolloutBlockingPods returns pods that belong to an old replica set of a deployment in the
+// middle of a rolling update. The deployment controller is about to remove them anyway, so they can be
+// evicted right away instead of the drain and the rollout waiting for each other.
+func GetDeploymentRolloutBlockingPods(ctx context.Context, client client.Interface, pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	oldReplicaSets := map[string]bool{}
+	for _, pod := range pods {
+		if err := ctx.Err(); err != nil {
+			return []*apiv1.Pod{}, err
+		}
+		sr, err := CreatorRef(pod)
+		if err != nil {
+			return []*apiv1.Pod{}, fmt.Errorf("failed to obtain creator reference of %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if sr == nil || sr.Reference.Kind != "ReplicaSet" {
+			continue
+		}
+		key := sr.Reference.Namespace + "/" + sr.Reference.Name
+		old, found := oldReplicaSets[key]
+		if !found {
+			old, err = isOldReplicaSetOfRollout(client, sr.Reference.Namespace, sr.Reference.Name)
+			if err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			oldReplicaSets[key] = old
+		}
+		if old {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+// isOldReplicaSetOfRollout checks whether the replica set belongs to a deployment that is being rolled
+// out to a newer revision.
+func isOldReplicaSetOfRollout(client client.Interface, namespace, name string) (bool, error) {
+	rs, err := client.Extensions().ReplicaSets(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get replica set %s/%s: %v", namespace, name, err)
+	}
+	deployment, err := getReplicaSetDeployment(client, rs)
+	if err != nil || deployment == nil {
+		return false, err
+	}
+	return deployment.Status.UpdatedReplicas < deployment.Status.Replicas &&
+		rs.Annotations[revisionAnnotation] != deployment.Annotations[revisionAnnotation], nil
+}
+
+// getReplicaSetDeployment returns the deployment whose selector matches the pod template of rs, or nil
+// if there is none.
+func getReplicaSetDeployment(client client.Interface, rs *extensions.ReplicaSet) (*extensions.Deployment, error) {
+	deploymentList, err := client.Extensions().Deployments(rs.Namespace).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in %s: %v", rs.Namespace, err)
+	}
+	return deploymentForLabels(deploymentList.Items, rs.Namespace, rs.Spec.Template.Labels)
+}
+
+// GetBlueGreenTransitionPods returns pods of deployments in the middle of a blue-green switch, i.e. with
+// more than one replica set scaled above zero. Evicting them during the switch may make requests go to
+// the old version unexpectedly.
+func GetBlueGreenTransitionPods(ctx context.Context, client client.Interface,
+	pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	transitioning := map[string]bool{}
+	for _, pod := range pods {
+		if err := ctx.Err(); err != nil {
+			return []*apiv1.Pod{}, err
+		}
+		sr, err := CreatorRef(pod)
+		if err != nil {
+			return []*apiv1.Pod{}, fmt.Errorf("failed to obtain creator reference of %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if sr == nil || sr.Reference.Kind != "ReplicaSet" {
+			continue
+		}
+		key := sr.Reference.Namespace + "/" + sr.Reference.Name
+		inTransition, found := transitioning[key]
+		if !found {
+			inTransition, err = isInBlueGreenTransition(client, sr.Reference.Namespace, sr.Reference.Name)
+			if err != nil {
+				return []*apiv1.Pod{}, err
+			}
+			transitioning[key] = inTransition
+		}
+		if inTransition {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+// isInBlueGreenTransition checks whether the deployment of the replica set has more than one replica
+// set scaled above zero.
+func isInBlueGreenTransition(client client.Interface, namespace, name string) (bool, error) {
+	rs, err := client.Extensions().ReplicaSets(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get replica set %s/%s: %v", namespace, name, err)
+	}
+	deployment, err := getReplicaSetDeployment(client, rs)
+	if err != nil || deployment == nil {
+		return false, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector of deployment %s/%s: %v", namespace, deployment.Name, err)
+	}
+	rsList, err := client.Extensions().ReplicaSets(namespace).List(apiv1.ListOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to list replica sets in %s: %v", namespace, err)
+	}
+	active := 0
+	for i := range rsList.Items {
+		other := &rsList.Items[i]
+		if other.Spec.Replicas != nil && *other.Spec.Replicas > 0 &&
+			selector.Matches(labels.Set(other.Spec.Template.Labels)) {
+			active++
+		}
+	}
+	return active > 1, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensions "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestDeployment(name string, desired, replicas, updated, available int32) *extensions.Deployment {
+	return &extensions.Deployment{
+		ObjectMeta: apiv1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       extensions.DeploymentSpec{Replicas: &desired},
+		Status: extensions.DeploymentStatus{
+			Replicas:          replicas,
+			UpdatedReplicas:   updated,
+			AvailableReplicas: available,
+		},
+	}
+}
+
+func buildTestReplicaSet(name, revision string, replicas int32) *extensions.ReplicaSet {
+	return &extensions.ReplicaSet{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{revisionAnnotation: revision},
+		},
+		Spec: extensions.ReplicaSetSpec{
+			Replicas: &replicas,
+			Template: apiv1.PodTemplateSpec{ObjectMeta: apiv1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+		},
+	}
+}
+
+func buildReplicaSetPod(name, rsName string) *apiv1.Pod {
+	pod := buildReplicatedPod(name, map[string]string{"app": "web"})
+	pod.Annotations[apiv1.CreatedByAnnotation] = "{\"kind\":\"SerializedReference\",\"apiVersion\":\"v1\"," +
+		"\"reference\":{\"kind\":\"ReplicaSet\",\"namespace\":\"default\",\"name\":\"" + rsName + "\"}}"
+	return pod
+}
+
+func TestIsRolloutAtLimit(t *testing.T) {
+	assert.False(t, isRolloutAtLimit(buildTestDeployment("done", 3, 3, 3, 3), 1, 1))
+	assert.False(t, isRolloutAtLimit(buildTestDeployment("room", 3, 3, 1, 3), 1, 1))
+	assert.True(t, isRolloutAtLimit(buildTestDeployment("surge", 3, 4, 1, 3), 1, 1))
+	assert.True(t, isRolloutAtLimit(buildTestDeployment("unavailable", 3, 3, 1, 2), 1, 1))
+
+	// Both limits at 0 mean maxUnavailable 1.
+	assert.False(t, isRolloutAtLimit(buildTestDeployment("room", 3, 3, 1, 3), 0, 0))
+	assert.True(t, isRolloutAtLimit(buildTestDeployment("unavailable", 3, 3, 1, 2), 0, 0))
+}
+
+func TestGetPodsForDrainRespectingRollingUpdate(t *testing.T) {
+	rolloutCheckInterval = time.Millisecond
+	rsPod := buildReplicatedPod("rs", nil)
+
+	fakeClient := fake.NewSimpleClientset(buildTestDeployment("done", 3, 3, 3, 3), rsPod)
+	pods, err := GetPodsForDrainRespectingRollingUpdate(context.Background(), fakeClient, "node", 1, 1,
+		time.Second, DrainOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, pods, 1) {
+		assert.Equal(t, "rs", pods[0].Name)
+	}
+
+	fakeClient = fake.NewSimpleClientset(buildTestDeployment("surge", 3, 4, 1, 3), rsPod)
+	_, err = GetPodsForDrainRespectingRollingUpdate(context.Background(), fakeClient, "node", 1, 1,
+		10*time.Millisecond, DrainOptions{})
+	assert.Error(t, err)
+}
+
+func TestGetDeploymentRolloutBlockingPods(t *testing.T) {
+	deployment := buildTestDeployment("web", 3, 4, 1, 3)
+	deployment.Annotations = map[string]string{revisionAnnotation: "2"}
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	oldPod := buildReplicaSetPod("old", "web-1")
+	newPod := buildReplicaSetPod("new", "web-2")
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	fakeClient := fake.NewSimpleClientset(deployment, buildTestReplicaSet("web-1", "1", 1), buildTestReplicaSet("web-2", "2", 3))
+
+	pods, err := GetDeploymentRolloutBlockingPods(context.Background(), fakeClient, []*apiv1.Pod{oldPod, newPod, dsPod})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{oldPod}, pods)
+
+	done := buildTestDeployment("web", 3, 3, 3, 3)
+	done.Annotations = deployment.Annotations
+	done.Spec.Selector = deployment.Spec.Selector
+	fakeClient = fake.NewSimpleClientset(done, buildTestReplicaSet("web-1", "1", 1), buildTestReplicaSet("web-2", "2", 3))
+	pods, err = GetDeploymentRolloutBlockingPods(context.Background(), fakeClient, []*apiv1.Pod{oldPod, newPod})
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+}
+
+func TestGetBlueGreenTransitionPods(t *testing.T) {
+	deployment := buildTestDeployment("web", 3, 3, 3, 3)
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	blue := buildReplicaSetPod("blue", "web-1")
+	green := buildReplicaSetPod("green", "web-2")
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+
+	fakeClient := fake.NewSimpleClientset(deployment, buildTestReplicaSet("web-1", "1", 3), buildTestReplicaSet("web-2", "2", 3))
+	pods, err := GetBlueGreenTransitionPods(context.Background(), fakeClient, []*apiv1.Pod{blue, green, dsPod})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{blue, green}, pods)
+
+	fakeClient = fake.NewSimpleClientset(deployment, buildTestReplicaSet("web-1", "1", 0), buildTestReplicaSet("web-2", "2", 3))
+	pods, err = GetBlueGreenTransitionPods(context.Background(), fakeClient, []*apiv1.Pod{green})
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+
+	// A deployment with an empty selector doesn't own every replica set.
+	catchAll := buildTestDeployment("all", 1, 1, 1, 1)
+	catchAll.Spec.Selector = &metav1.LabelSelector{}
+	fakeClient = fake.NewSimpleClientset(catchAll, buildTestReplicaSet("web-1", "1", 3), buildTestReplicaSet("web-2", "2", 3))
+	pods, err = GetBlueGreenTransitionPods(context.Background(), fakeClient, []*apiv1.Pod{blue, green})
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+}
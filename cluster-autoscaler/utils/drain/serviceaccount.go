@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// defaultServiceAccountName is used by pods that don't set ServiceAccountName.
+const defaultServiceAccountName = "default"
+
+// GetOrphanedServiceAccountPods returns pods whose service account no longer exists. Such pods are
+// already broken and can be evicted without fear of making things worse. Service accounts are
+// listed once per namespace.
+func GetOrphanedServiceAccountPods(ctx context.Context, client client.Interface, pods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	accountsByNamespace := make(map[string]map[string]bool)
+
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		if err := ctx.Err(); err != nil {
+			return []*apiv1.Pod{}, err
+		}
+		accounts, found := accountsByNamespace[pod.Namespace]
+		if !found {
+			accountList, err := client.Core().ServiceAccounts(pod.Namespace).List(apiv1.ListOptions{})
+			if err != nil {
+				return []*apiv1.Pod{}, fmt.Errorf("failed to list service accounts in %s: %v", pod.Namespace, err)
+			}
+			accounts = make(map[string]bool, len(accountList.Items))
+			for _, account := range accountList.Items {
+				accounts[account.Name] = true
+			}
+			accountsByNamespace[pod.Namespace] = accounts
+		}
+		name := pod.Spec.ServiceAccountName
+		if name == "" {
+			name = defaultServiceAccountName
+		}
+		if !accounts[name] {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
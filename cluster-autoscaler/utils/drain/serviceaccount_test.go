@@ -0,0 +1,52 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrphanedServiceAccountPods(t *testing.T) {
+	defaultAccount := &apiv1.ServiceAccount{ObjectMeta: apiv1.ObjectMeta{Name: "default", Namespace: "default"}}
+	webAccount := &apiv1.ServiceAccount{ObjectMeta: apiv1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+	webPod := buildReplicatedPod("web", nil)
+	webPod.Spec.ServiceAccountName = "web"
+	defaultPod := buildReplicatedPod("default", nil)
+	orphanedPod := buildReplicatedPod("orphaned", nil)
+	orphanedPod.Spec.ServiceAccountName = "deleted"
+
+	fakeClient := fake.NewSimpleClientset(defaultAccount, webAccount)
+	pods, err := GetOrphanedServiceAccountPods(context.Background(), fakeClient,
+		[]*apiv1.Pod{webPod, defaultPod, orphanedPod})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{orphanedPod}, pods)
+
+	listCalls := 0
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() == "list" {
+			listCalls++
+		}
+	}
+	assert.Equal(t, 1, listCalls)
+}
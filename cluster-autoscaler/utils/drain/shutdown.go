@@ -0,0 +1,196 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// PodTerminationRecord stores an observed termination duration of a pod with the given fingerprint.
+type PodTerminationRecord struct {
+	// Fingerprint identifies the images and commands of the pod, see PodFingerprint.
+	Fingerprint string
+	// Duration is the time it took the pod to shut down.
+	Duration time.Duration
+}
+
+// PodFingerprint returns a key identifying pods running the same images with the same commands.
+func PodFingerprint(pod *apiv1.Pod) string {
+	hash := fnv.New64a()
+	for _, container := range pod.Spec.Containers {
+		fmt.Fprintf(hash, "%s|%s|%s;", container.Image,
+			strings.Join(container.Command, " "), strings.Join(container.Args, " "))
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// EstimatePodShutdownTime returns the longest termination duration observed for pods with the same
+// fingerprint. Without any history the pod's termination grace period is returned.
+func EstimatePodShutdownTime(pod *apiv1.Pod, terminationHistory []PodTerminationRecord) time.Duration {
+	fingerprint := PodFingerprint(pod)
+	var estimate time.Duration
+	found := false
+	for _, record := range terminationHistory {
+		if record.Fingerprint == fingerprint && (!found || record.Duration > estimate) {
+			estimate = record.Duration
+			found = true
+		}
+	}
+	if !found {
+		return terminationGracePeriod(pod)
+	}
+	return estimate
+}
+
+// terminationGracePeriod returns the termination grace period of the pod, applying the API default if unset.
+func terminationGracePeriod(pod *apiv1.Pod) time.Duration {
+	if pod.Spec.TerminationGracePeriodSeconds == nil {
+		return apiv1.DefaultTerminationGracePeriodSeconds * time.Second
+	}
+	return time.Duration(*pod.Spec.TerminationGracePeriodSeconds) * time.Second
+}
+
+// HasPreStopHook checks whether any container of the pod registers a PreStop hook.
+func HasPreStopHook(pod *apiv1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Lifecycle != nil && container.Lifecycle.PreStop != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// PreStopTimeout returns how long the PreStop hooks of the pod may run before the containers are
+// sent SIGTERM. Kubelet doesn't bound the hooks separately, they can use the whole termination
+// grace period. Returns 0 for pods without PreStop hooks.
+func PreStopTimeout(pod *apiv1.Pod) time.Duration {
+	if !HasPreStopHook(pod) {
+		return 0
+	}
+	return terminationGracePeriod(pod)
+}
+
+// ShutdownHistoryCache keeps the most recent termination records per pod fingerprint. It is safe
+// for concurrent use and can be persisted to a ConfigMap to survive autoscaler restarts.
+type ShutdownHistoryCache struct {
+	mutex      sync.Mutex
+	maxRecords int
+	records    map[string][]time.Duration
+}
+
+// NewShutdownHistoryCache builds a cache keeping up to maxRecords durations per fingerprint. maxRecords
+// has to be positive.
+func NewShutdownHistoryCache(maxRecords int) (*ShutdownHistoryCache, error) {
+	if maxRecords <= 0 {
+		return nil, fmt.Errorf("max records must be positive, got %d", maxRecords)
+	}
+	return &ShutdownHistoryCache{
+		maxRecords: maxRecords,
+		records:    make(map[string][]time.Duration),
+	}, nil
+}
+
+// Add stores a termination record, dropping the oldest record for the fingerprint if needed.
+func (c *ShutdownHistoryCache) Add(record PodTerminationRecord) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	durations := append(c.records[record.Fingerprint], record.Duration)
+	if len(durations) > c.maxRecords {
+		durations = durations[len(durations)-c.maxRecords:]
+	}
+	c.records[record.Fingerprint] = durations
+}
+
+// Records returns all stored termination records.
+func (c *ShutdownHistoryCache) Records() []PodTerminationRecord {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	result := []PodTerminationRecord{}
+	for fingerprint, durations := range c.records {
+		for _, duration := range durations {
+			result = append(result, PodTerminationRecord{Fingerprint: fingerprint, Duration: duration})
+		}
+	}
+	return result
+}
+
+// SaveToConfigMap writes the cache content to the given ConfigMap, creating it if needed.
+func (c *ShutdownHistoryCache) SaveToConfigMap(client client.Interface, namespace, name string) error {
+	c.mutex.Lock()
+	data := make(map[string]string, len(c.records))
+	for fingerprint, durations := range c.records {
+		values := make([]string, 0, len(durations))
+		for _, duration := range durations {
+			values = append(values, duration.String())
+		}
+		data[fingerprint] = strings.Join(values, ",")
+	}
+	c.mutex.Unlock()
+
+	configMap, err := client.Core().ConfigMaps(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		_, err = client.Core().ConfigMaps(namespace).Create(&apiv1.ConfigMap{
+			ObjectMeta: apiv1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       data,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	configMap.Data = data
+	_, err = client.Core().ConfigMaps(namespace).Update(configMap)
+	return err
+}
+
+// LoadShutdownHistoryCacheFromConfigMap builds a cache from a ConfigMap written by SaveToConfigMap.
+// An empty cache is returned if the ConfigMap doesn't exist. Fingerprints without records are skipped.
+func LoadShutdownHistoryCacheFromConfigMap(client client.Interface, namespace, name string,
+	maxRecords int) (*ShutdownHistoryCache, error) {
+	cache, err := NewShutdownHistoryCache(maxRecords)
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := client.Core().ConfigMaps(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for fingerprint, values := range configMap.Data {
+		if values == "" {
+			continue
+		}
+		for _, value := range strings.Split(values, ",") {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse shutdown history of %s in %s/%s: %v", fingerprint, namespace, name, err)
+			}
+			cache.Add(PodTerminationRecord{Fingerprint: fingerprint, Duration: duration})
+		}
+	}
+	return cache, nil
+}
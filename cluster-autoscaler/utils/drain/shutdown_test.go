@@ -0,0 +1,112 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatePodShutdownTime(t *testing.T) {
+	grace := int64(45)
+	pod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: apiv1.PodSpec{
+			TerminationGracePeriodSeconds: &grace,
+			Containers:                    []apiv1.Container{{Image: "nginx:1.11", Command: []string{"nginx"}}},
+		},
+	}
+	other := &apiv1.Pod{
+		Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Image: "redis:3"}}},
+	}
+
+	assert.Equal(t, 45*time.Second, EstimatePodShutdownTime(pod, nil))
+	assert.Equal(t, 30*time.Second, EstimatePodShutdownTime(other, nil))
+
+	history := []PodTerminationRecord{
+		{Fingerprint: PodFingerprint(pod), Duration: 5 * time.Second},
+		{Fingerprint: PodFingerprint(pod), Duration: 12 * time.Second},
+		{Fingerprint: PodFingerprint(other), Duration: time.Minute},
+	}
+	assert.Equal(t, 12*time.Second, EstimatePodShutdownTime(pod, history))
+}
+
+func TestPreStopTimeout(t *testing.T) {
+	grace := int64(60)
+	hooked := &apiv1.Pod{
+		Spec: apiv1.PodSpec{
+			TerminationGracePeriodSeconds: &grace,
+			Containers: []apiv1.Container{
+				{Name: "app"},
+				{Name: "proxy", Lifecycle: &apiv1.Lifecycle{
+					PreStop: &apiv1.Handler{Exec: &apiv1.ExecAction{Command: []string{"sleep", "10"}}},
+				}},
+			},
+		},
+	}
+	plain := &apiv1.Pod{
+		Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Name: "app", Lifecycle: &apiv1.Lifecycle{}}}},
+	}
+
+	assert.True(t, HasPreStopHook(hooked))
+	assert.Equal(t, 60*time.Second, PreStopTimeout(hooked))
+	assert.False(t, HasPreStopHook(plain))
+	assert.Equal(t, time.Duration(0), PreStopTimeout(plain))
+}
+
+func TestShutdownHistoryCache(t *testing.T) {
+	_, err := NewShutdownHistoryCache(0)
+	assert.Error(t, err)
+	_, err = NewShutdownHistoryCache(-1)
+	assert.Error(t, err)
+
+	cache, err := NewShutdownHistoryCache(2)
+	assert.NoError(t, err)
+	cache.Add(PodTerminationRecord{Fingerprint: "a", Duration: time.Second})
+	cache.Add(PodTerminationRecord{Fingerprint: "a", Duration: 2 * time.Second})
+	cache.Add(PodTerminationRecord{Fingerprint: "a", Duration: 3 * time.Second})
+	assert.Equal(t, []PodTerminationRecord{
+		{Fingerprint: "a", Duration: 2 * time.Second},
+		{Fingerprint: "a", Duration: 3 * time.Second},
+	}, cache.Records())
+
+	fakeClient := fake.NewSimpleClientset()
+	assert.NoError(t, cache.SaveToConfigMap(fakeClient, "kube-system", "shutdown-history"))
+	cache.Add(PodTerminationRecord{Fingerprint: "b", Duration: time.Second})
+	assert.NoError(t, cache.SaveToConfigMap(fakeClient, "kube-system", "shutdown-history"))
+
+	loaded, err := LoadShutdownHistoryCacheFromConfigMap(fakeClient, "kube-system", "shutdown-history", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, cache.records, loaded.records)
+
+	empty, err := LoadShutdownHistoryCacheFromConfigMap(fakeClient, "kube-system", "missing", 2)
+	assert.NoError(t, err)
+	assert.Empty(t, empty.Records())
+
+	fakeClient = fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "shutdown-history"},
+		Data:       map[string]string{"a": ""},
+	})
+	empty, err = LoadShutdownHistoryCacheFromConfigMap(fakeClient, "kube-system", "shutdown-history", 2)
+	assert.NoError(t, err)
+	assert.Empty(t, empty.Records())
+}
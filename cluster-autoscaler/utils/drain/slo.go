@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"sort"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+	"k8s.io/kubernetes/pkg/labels"
+
+	"github.com/golang/glog"
+)
+
+// SLOTarget is the availability objective of the pods of a service.
+type SLOTarget struct {
+	Namespace string
+	Selector  *metav1.LabelSelector
+	// Availability is the fraction of replicas that must stay available, e.g. 0.999.
+	Availability float64
+}
+
+// SLORegistry maps service names to their SLO targets.
+type SLORegistry map[string]SLOTarget
+
+// ServiceImpact describes the predicted availability of a service while a node is drained.
+type ServiceImpact struct {
+	Service string
+	// Replicas is the number of pods of the service and Disrupted the number of its ready pods that
+	// would be evicted.
+	Replicas  int
+	Disrupted int
+	// Unprotected is the number of disrupted pods that no PodDisruptionBudget covers.
+	Unprotected           int
+	PredictedAvailability float64
+	Target                float64
+}
+
+// ServiceImpactReport lists the services whose SLO would be violated by a drain.
+type ServiceImpactReport struct {
+	Violations []ServiceImpact
+}
+
+// ComputeServiceImpact estimates, for every service in sloRegistry, the fraction of its replicas in allPods
+// that would remain available while the pods are evicted, and reports the services for which it falls
+// below their target. Services without pods among the evicted ones are not affected.
+func ComputeServiceImpact(pods []*apiv1.Pod, allPods []*apiv1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget,
+	sloRegistry SLORegistry) ServiceImpactReport {
+	names := make([]string, 0, len(sloRegistry))
+	for name := range sloRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := ServiceImpactReport{Violations: []ServiceImpact{}}
+	for _, name := range names {
+		target := sloRegistry[name]
+		if target.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+		if err != nil {
+			glog.Warningf("Invalid selector in SLO target %s: %v", name, err)
+			continue
+		}
+		if selector.Empty() {
+			continue
+		}
+		impact := ServiceImpact{Service: name, Target: target.Availability}
+		for _, pod := range pods {
+			if matchesSLOTarget(target, selector, pod) && podConditionIsTrue(pod, apiv1.PodReady) {
+				impact.Disrupted++
+				if len(GetPodPDBs(pod, pdbs)) == 0 {
+					impact.Unprotected++
+				}
+			}
+		}
+		if impact.Disrupted == 0 {
+			continue
+		}
+		available := 0
+		for _, pod := range allPods {
+			if !matchesSLOTarget(target, selector, pod) {
+				continue
+			}
+			impact.Replicas++
+			if podConditionIsTrue(pod, apiv1.PodReady) {
+				available++
+			}
+		}
+		available -= impact.Disrupted
+		if impact.Replicas > 0 && available > 0 {
+			impact.PredictedAvailability = float64(available) / float64(impact.Replicas)
+		}
+		if impact.PredictedAvailability < impact.Target {
+			report.Violations = append(report.Violations, impact)
+		}
+	}
+	return report
+}
+
+func matchesSLOTarget(target SLOTarget, selector labels.Selector, pod *apiv1.Pod) bool {
+	return pod.Namespace == target.Namespace && selector.Matches(labels.Set(pod.Labels))
+}
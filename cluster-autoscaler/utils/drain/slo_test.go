@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	policyv1beta1 "k8s.io/kubernetes/pkg/apis/policy/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeServiceImpact(t *testing.T) {
+	buildReadyPods := func(app string, count int) []*apiv1.Pod {
+		pods := []*apiv1.Pod{}
+		for i := 0; i < count; i++ {
+			pod := buildReplicatedPod(fmt.Sprintf("%s%d", app, i), map[string]string{"app": app})
+			pod.Status.Conditions = []apiv1.PodCondition{{Type: apiv1.PodReady, Status: apiv1.ConditionTrue}}
+			pods = append(pods, pod)
+		}
+		return pods
+	}
+	web := buildReadyPods("web", 4)
+	db := buildReadyPods("db", 10)
+	cache := buildReadyPods("cache", 2)
+	allPods := append(append(append([]*apiv1.Pod{}, web...), db...), cache...)
+	pods := []*apiv1.Pod{web[0], web[1], db[0]}
+	pdbs := []*policyv1beta1.PodDisruptionBudget{buildTestPDB("db", map[string]string{"app": "db"}, 1)}
+
+	registry := SLORegistry{
+		"web": {Namespace: "default", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Availability: 0.9},
+		"db": {Namespace: "default", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Availability: 0.8},
+		"cache": {Namespace: "default", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cache"}},
+			Availability: 0.999},
+		"other-namespace": {Namespace: "kube-system",
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, Availability: 0.9},
+	}
+
+	report := ComputeServiceImpact(pods, allPods, pdbs, registry)
+	assert.Equal(t, []ServiceImpact{{
+		Service:               "web",
+		Replicas:              4,
+		Disrupted:             2,
+		Unprotected:           2,
+		PredictedAvailability: 0.5,
+		Target:                0.9,
+	}}, report.Violations)
+
+	registry["db"] = SLOTarget{Namespace: "default",
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}, Availability: 0.95}
+	report = ComputeServiceImpact(pods, allPods, pdbs, registry)
+	assert.Equal(t, 2, len(report.Violations))
+	assert.Equal(t, "db", report.Violations[0].Service)
+	assert.Equal(t, 0, report.Violations[0].Unprotected)
+	assert.InDelta(t, 0.9, report.Violations[0].PredictedAvailability, 1e-9)
+
+	assert.Empty(t, ComputeServiceImpact([]*apiv1.Pod{}, allPods, pdbs, registry).Violations)
+}
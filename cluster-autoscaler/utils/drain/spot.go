@@ -0,0 +1,134 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// spotInterruptionTimeout bounds HandleSpotInterruption so that it completes within the termination
+	// notice (2 minutes on most providers).
+	spotInterruptionTimeout = 90 * time.Second
+	// spotInterruptionGracePeriod is the longest termination grace period given to pods of a preempted node.
+	spotInterruptionGracePeriod = 60 * time.Second
+	// spotPodCheckInterval is how often pods are checked while waiting for them to terminate.
+	spotPodCheckInterval = 2 * time.Second
+)
+
+// HandleSpotInterruption drains a node that is about to be preempted. It cordons the node and deletes all
+// its pods except DaemonSet and mirror pods in parallel, then waits for them to terminate. The
+// preemption can't be avoided, so pods are deleted regardless of PodDisruptionBudgets and the checks
+// configured in opts (only CheckReferences is honored). Pods get at most spotInterruptionGracePeriod
+// to terminate and the whole operation at most spotInterruptionTimeout.
+func HandleSpotInterruption(ctx context.Context, client client.Interface, nodeName string, opts DrainOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, spotInterruptionTimeout)
+	defer cancel()
+
+	node, err := client.Core().Nodes().Get(nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %v", nodeName, err)
+	}
+	if !node.Spec.Unschedulable {
+		node.Spec.Unschedulable = true
+		if _, err := client.Core().Nodes().Update(node); err != nil {
+			return fmt.Errorf("failed to cordon node %s: %v", nodeName, err)
+		}
+	}
+
+	allPods, err := listPodsOnNode(client, nodeName)
+	if err != nil {
+		return err
+	}
+	opts.DeleteAll = true
+	pods, err := GetPodsForDeletionOnNodeDrainWithOptions(allPods, api.Codecs.UniversalDecoder(), client, opts)
+	if err != nil {
+		return err
+	}
+
+	results := make(chan error, len(pods))
+	for _, pod := range pods {
+		go func(pod *apiv1.Pod) {
+			gracePeriod := int64(terminationGracePeriod(pod) / time.Second)
+			if maxGracePeriod := int64(spotInterruptionGracePeriod / time.Second); gracePeriod > maxGracePeriod {
+				gracePeriod = maxGracePeriod
+			}
+			err := client.Core().Pods(pod.Namespace).Delete(pod.Name, &apiv1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+			if err != nil && !errors.IsNotFound(err) {
+				results <- fmt.Errorf("failed to delete %s/%s: %v", pod.Namespace, pod.Name, err)
+				return
+			}
+			results <- nil
+		}(pod)
+	}
+	var deleteErr error
+	for range pods {
+		if err := <-results; err != nil {
+			glog.Errorf("Spot interruption of %s: %v", nodeName, err)
+			deleteErr = err
+		}
+	}
+	if deleteErr != nil {
+		return deleteErr
+	}
+	return waitForPodsToTerminate(ctx, client, pods)
+}
+
+// waitForPodsToTerminate polls the pods until all of them are gone or ctx is done. A pod with the same
+// name but a different UID (e.g. a StatefulSet pod recreated elsewhere) counts as gone.
+func waitForPodsToTerminate(ctx context.Context, client client.Interface, pods []*apiv1.Pod) error {
+	remaining := pods
+	for {
+		stillPresent := []*apiv1.Pod{}
+		for _, pod := range remaining {
+			fresh, err := client.Core().Pods(pod.Namespace).Get(pod.Name)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			if fresh.UID != pod.UID {
+				continue
+			}
+			stillPresent = append(stillPresent, pod)
+		}
+		remaining = stillPresent
+		if len(remaining) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			names := make([]string, 0, len(remaining))
+			for _, pod := range remaining {
+				names = append(names, pod.Namespace+"/"+pod.Name)
+			}
+			return fmt.Errorf("pods %s not terminated: %v", strings.Join(names, ","), ctx.Err())
+		case <-time.After(spotPodCheckInterval):
+		}
+	}
+}
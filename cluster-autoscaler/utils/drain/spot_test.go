@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSpotInterruption(t *testing.T) {
+	node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: "node"}}
+	rsPod := buildReplicatedPod("rs", nil)
+	nakedPod := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "naked", Namespace: "default"},
+		Spec:       apiv1.PodSpec{NodeName: "node"},
+	}
+	dsPod := buildReplicatedPod("ds", nil)
+	dsPod.Annotations[apiv1.CreatedByAnnotation] = daemonSetCreatedBy
+	fakeClient := fake.NewSimpleClientset(node, rsPod, nakedPod, dsPod)
+
+	err := HandleSpotInterruption(context.Background(), fakeClient, "node", DrainOptions{})
+	assert.NoError(t, err)
+
+	cordoned, err := fakeClient.Core().Nodes().Get("node")
+	assert.NoError(t, err)
+	assert.True(t, cordoned.Spec.Unschedulable)
+
+	podList, err := fakeClient.Core().Pods(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, podList.Items, 1) {
+		assert.Equal(t, "ds", podList.Items[0].Name)
+	}
+
+	assert.Error(t, HandleSpotInterruption(context.Background(), fakeClient, "missing", DrainOptions{}))
+}
+
+func TestWaitForPodsToTerminate(t *testing.T) {
+	pod := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "web-0", Namespace: "default", UID: "old"}}
+	recreated := &apiv1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: "web-0", Namespace: "default", UID: "new"},
+		Spec:       apiv1.PodSpec{NodeName: "other"},
+	}
+	assert.NoError(t, waitForPodsToTerminate(context.Background(), fake.NewSimpleClientset(recreated), []*apiv1.Pod{pod}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, waitForPodsToTerminate(ctx, fake.NewSimpleClientset(pod), []*apiv1.Pod{pod}))
+}
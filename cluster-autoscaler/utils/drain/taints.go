@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/golang/glog"
+)
+
+// GetResourceTolerationPinnedPods returns pods that tolerate all nodeTaints and request an extended
+// resource under which the node is tainted (e.g. "nvidia.com/gpu": NoSchedule). Such taints keep other
+// pods off nodes with the resource, so these pods can only move to another node with the same taint.
+func GetResourceTolerationPinnedPods(pods []*apiv1.Pod, nodeTaints []apiv1.Taint) []*apiv1.Pod {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		tolerations, err := apiv1.GetTolerationsFromPodAnnotations(pod.Annotations)
+		if err != nil {
+			glog.Warningf("Failed to get tolerations of %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		pinned := false
+		toleratesAll := true
+		for i := range nodeTaints {
+			taint := &nodeTaints[i]
+			if !apiv1.TaintToleratedByTolerations(taint, tolerations) {
+				toleratesAll = false
+				break
+			}
+			if taint.Effect != apiv1.TaintEffectPreferNoSchedule && requestsResource(pod, apiv1.ResourceName(taint.Key)) {
+				pinned = true
+			}
+		}
+		if pinned && toleratesAll {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// ValidateReplacementTolerations splits pods into the ones that tolerate the NoSchedule and NoExecute taints
+// of at least one schedulable node other than drainNode, and the ones that would be stuck pending after
+// eviction because no such node accepts them. Nodes with invalid taints are ignored and pods with invalid
+// tolerations are considered stuck.
+func ValidateReplacementTolerations(pods []*apiv1.Pod, nodes []*apiv1.Node,
+	drainNode *apiv1.Node) (replaceable []*apiv1.Pod, stuck []*apiv1.Pod) {
+	nodeTaints := [][]apiv1.Taint{}
+	for _, node := range nodes {
+		if node.Name == drainNode.Name || node.Spec.Unschedulable {
+			continue
+		}
+		taints, err := apiv1.GetTaintsFromNodeAnnotations(node.Annotations)
+		if err != nil {
+			glog.Warningf("Failed to get taints of node %s: %v", node.Name, err)
+			continue
+		}
+		nodeTaints = append(nodeTaints, taints)
+	}
+
+	replaceable = []*apiv1.Pod{}
+	stuck = []*apiv1.Pod{}
+	for _, pod := range pods {
+		tolerations, err := apiv1.GetTolerationsFromPodAnnotations(pod.Annotations)
+		if err != nil {
+			glog.Warningf("Failed to get tolerations of %s/%s: %v", pod.Namespace, pod.Name, err)
+			stuck = append(stuck, pod)
+			continue
+		}
+		fits := false
+		for _, taints := range nodeTaints {
+			if toleratesSchedulingTaints(taints, tolerations) {
+				fits = true
+				break
+			}
+		}
+		if fits {
+			replaceable = append(replaceable, pod)
+		} else {
+			stuck = append(stuck, pod)
+		}
+	}
+	return replaceable, stuck
+}
+
+// toleratesSchedulingTaints checks whether tolerations tolerate all taints that prevent scheduling.
+func toleratesSchedulingTaints(taints []apiv1.Taint, tolerations []apiv1.Toleration) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == apiv1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !apiv1.TaintToleratedByTolerations(taint, tolerations) {
+			return false
+		}
+	}
+	return true
+}
+
+func requestsResource(pod *apiv1.Pod, name apiv1.ResourceName) bool {
+	for _, container := range pod.Spec.Containers {
+		if value, found := container.Resources.Requests[name]; found && !value.IsZero() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResourceTolerationPinnedPods(t *testing.T) {
+	gpuToleration := `[{"key":"nvidia.com/gpu","operator":"Exists","effect":"NoSchedule"}]`
+	nodeTaints := []apiv1.Taint{{Key: "nvidia.com/gpu", Value: "present", Effect: apiv1.TaintEffectNoSchedule}}
+
+	pinned := buildGPUPod("pinned", "nvidia.com/gpu", 1)
+	pinned.Annotations[apiv1.TolerationsAnnotationKey] = gpuToleration
+	tolerating := buildGPUPod("tolerating", apiv1.ResourceCPU, 1)
+	tolerating.Annotations[apiv1.TolerationsAnnotationKey] = gpuToleration
+	intolerant := buildGPUPod("intolerant", "nvidia.com/gpu", 1)
+
+	result := GetResourceTolerationPinnedPods([]*apiv1.Pod{pinned, tolerating, intolerant}, nodeTaints)
+	assert.Equal(t, []*apiv1.Pod{pinned}, result)
+
+	assert.Empty(t, GetResourceTolerationPinnedPods([]*apiv1.Pod{pinned}, nil))
+}
+
+func TestValidateReplacementTolerations(t *testing.T) {
+	buildTaintedNode := func(name, taints string) *apiv1.Node {
+		node := &apiv1.Node{ObjectMeta: apiv1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		if taints != "" {
+			node.Annotations[apiv1.TaintsAnnotationKey] = taints
+		}
+		return node
+	}
+	gpuTaint := `[{"key":"dedicated","value":"gpu","effect":"NoSchedule"}]`
+	draining := buildTaintedNode("draining", gpuTaint)
+	gpuNode := buildTaintedNode("gpu", gpuTaint)
+	preferNode := buildTaintedNode("prefer", `[{"key":"spot","value":"true","effect":"PreferNoSchedule"}]`)
+	cordoned := buildTaintedNode("cordoned", gpuTaint)
+	cordoned.Spec.Unschedulable = true
+
+	tolerating := buildGPUPod("tolerating", "nvidia.com/gpu", 1)
+	tolerating.Annotations[apiv1.TolerationsAnnotationKey] =
+		`[{"key":"dedicated","operator":"Equal","value":"gpu","effect":"NoSchedule"}]`
+	plain := buildGPUPod("plain", apiv1.ResourceCPU, 1)
+	invalid := buildGPUPod("invalid", apiv1.ResourceCPU, 1)
+	invalid.Annotations[apiv1.TolerationsAnnotationKey] = "junk"
+	pods := []*apiv1.Pod{tolerating, plain, invalid}
+
+	replaceable, stuck := ValidateReplacementTolerations(pods, []*apiv1.Node{draining, gpuNode, preferNode}, draining)
+	assert.Equal(t, []*apiv1.Pod{tolerating, plain}, replaceable)
+	assert.Equal(t, []*apiv1.Pod{invalid}, stuck)
+
+	replaceable, stuck = ValidateReplacementTolerations(pods, []*apiv1.Node{draining, gpuNode, cordoned}, draining)
+	assert.Equal(t, []*apiv1.Pod{tolerating}, replaceable)
+	assert.Equal(t, []*apiv1.Pod{plain, invalid}, stuck)
+
+	replaceable, stuck = ValidateReplacementTolerations(pods, []*apiv1.Node{draining}, draining)
+	assert.Empty(t, replaceable)
+	assert.Equal(t, pods, stuck)
+}
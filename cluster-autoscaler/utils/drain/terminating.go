@@ -0,0 +1,45 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	client "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
+)
+
+// GetPodsBeingEvicted returns pods that are already terminating, i.e. whose current version in the
+// API server has a deletion timestamp set. This happens when another autoscaler instance evicted them
+// first; evicting them again would only produce errors. Pods that are already gone are not returned.
+func GetPodsBeingEvicted(pods []*apiv1.Pod, client client.Interface) ([]*apiv1.Pod, error) {
+	result := []*apiv1.Pod{}
+	for _, pod := range pods {
+		fresh, err := client.Core().Pods(pod.Namespace).Get(pod.Name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if fresh.DeletionTimestamp != nil {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPodsBeingEvicted(t *testing.T) {
+	now := metav1.Now()
+	evicting := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "evicting", Namespace: "default", DeletionTimestamp: &now}}
+	running := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "running", Namespace: "default"}}
+	gone := &apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "gone", Namespace: "default"}}
+	fakeClient := fake.NewSimpleClientset(evicting, running)
+
+	stale := []*apiv1.Pod{
+		{ObjectMeta: apiv1.ObjectMeta{Name: "evicting", Namespace: "default"}},
+		running,
+		gone,
+	}
+	result, err := GetPodsBeingEvicted(stale, fakeClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{stale[0]}, result)
+}
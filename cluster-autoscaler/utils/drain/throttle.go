@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SharedDrainState tracks drains started across the cluster so that their rate can be limited. It is
+// safe for concurrent use.
+type SharedDrainState struct {
+	mutex     sync.Mutex
+	window    time.Duration
+	maxDrains int
+	// starts maps node names to the time their drain was allowed to start.
+	starts map[string]time.Time
+}
+
+// NewSharedDrainState returns a state allowing at most maxDrains drains to start within window. A
+// non-positive maxDrains allows none.
+func NewSharedDrainState(window time.Duration, maxDrains int) *SharedDrainState {
+	return &SharedDrainState{
+		window:    window,
+		maxDrains: maxDrains,
+		starts:    make(map[string]time.Time),
+	}
+}
+
+// AdaptiveDrainThrottle checks whether the drain of targetNode may start now. If the number of drains
+// started within the window of globalDrainState already reached its limit, proceed is false and
+// retryAfter tells when the oldest of them leaves the window. Otherwise the drain is recorded.
+// Asking again for a node that is already recorded doesn't count as a new drain.
+func AdaptiveDrainThrottle(ctx context.Context, targetNode string, globalDrainState *SharedDrainState) (proceed bool, retryAfter time.Duration, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, 0, err
+	}
+	proceed, retryAfter = globalDrainState.tryStart(targetNode, time.Now())
+	return proceed, retryAfter, nil
+}
+
+func (s *SharedDrainState) tryStart(node string, now time.Time) (bool, time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, found := s.starts[node]; found {
+		return true, 0
+	}
+	var oldest time.Time
+	for name, start := range s.starts {
+		if !start.Add(s.window).After(now) {
+			delete(s.starts, name)
+			continue
+		}
+		if oldest.IsZero() || start.Before(oldest) {
+			oldest = start
+		}
+	}
+	if len(s.starts) >= s.maxDrains {
+		if oldest.IsZero() {
+			// No drain to wait for, only possible if maxDrains is not positive.
+			return false, s.window
+		}
+		return false, oldest.Add(s.window).Sub(now)
+	}
+	s.starts[node] = now
+	return true, 0
+}
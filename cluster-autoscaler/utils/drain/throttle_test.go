@@ -0,0 +1,66 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedDrainStateTryStart(t *testing.T) {
+	state := NewSharedDrainState(time.Minute, 2)
+	now := time.Now()
+
+	proceed, _ := state.tryStart("n1", now)
+	assert.True(t, proceed)
+	proceed, _ = state.tryStart("n2", now.Add(20*time.Second))
+	assert.True(t, proceed)
+	proceed, retryAfter := state.tryStart("n3", now.Add(30*time.Second))
+	assert.False(t, proceed)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	// Already started drains are not throttled.
+	proceed, _ = state.tryStart("n1", now.Add(30*time.Second))
+	assert.True(t, proceed)
+
+	proceed, _ = state.tryStart("n3", now.Add(61*time.Second))
+	assert.True(t, proceed)
+
+	state = NewSharedDrainState(time.Minute, 0)
+	proceed, retryAfter = state.tryStart("n1", now)
+	assert.False(t, proceed)
+	assert.Equal(t, time.Minute, retryAfter)
+}
+
+func TestAdaptiveDrainThrottle(t *testing.T) {
+	state := NewSharedDrainState(time.Minute, 1)
+	proceed, _, err := AdaptiveDrainThrottle(context.Background(), "n1", state)
+	assert.NoError(t, err)
+	assert.True(t, proceed)
+	proceed, retryAfter, err := AdaptiveDrainThrottle(context.Background(), "n2", state)
+	assert.NoError(t, err)
+	assert.False(t, proceed)
+	assert.True(t, retryAfter > 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = AdaptiveDrainThrottle(ctx, "n3", state)
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// PreEvictionWebhook configures an external endpoint that approves pod evictions, e.g. as part of
+// a change-management process.
+type PreEvictionWebhook struct {
+	// URL is the endpoint the pods are POSTed to.
+	URL string
+	// CABundle is a PEM encoded CA bundle used to verify the endpoint. If empty the system roots are used.
+	CABundle []byte
+	// TimeoutSeconds limits the duration of a single request. 0 means no timeout.
+	TimeoutSeconds int
+}
+
+type evictionApprovalRequest struct {
+	Pod *apiv1.Pod `json:"pod"`
+}
+
+type evictionApprovalResponse struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+// RequestEvictionApproval sends the pod as JSON to the webhook and returns whether its eviction was
+// approved, together with the reason given by the webhook. The request is cancelled together with ctx.
+// Nothing in the drain logic calls it, callers that evict pods have to consult the webhook themselves
+// and keep unapproved pods (and their node) in place.
+func RequestEvictionApproval(ctx context.Context, webhook *PreEvictionWebhook, pod *apiv1.Pod) (bool, string, error) {
+	httpClient, err := webhook.httpClient()
+	if err != nil {
+		return false, "", err
+	}
+	body, err := json.Marshal(evictionApprovalRequest{Pod: pod})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to encode pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, "", fmt.Errorf("invalid pre-eviction webhook url: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, "", fmt.Errorf("failed to request eviction approval for %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, "", fmt.Errorf("eviction approval for %s/%s failed with status %s", pod.Namespace, pod.Name, resp.Status)
+	}
+	var approval evictionApprovalResponse
+	if err := json.NewDecoder(resp.Body).Decode(&approval); err != nil {
+		return false, "", fmt.Errorf("invalid eviction approval response for %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	return approval.Approved, approval.Reason, nil
+}
+
+func (w *PreEvictionWebhook) httpClient() (*http.Client, error) {
+	httpClient := &http.Client{Timeout: time.Duration(w.TimeoutSeconds) * time.Second}
+	if len(w.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(w.CABundle) {
+			return nil, fmt.Errorf("invalid CA bundle of pre-eviction webhook")
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	}
+	return httpClient, nil
+}
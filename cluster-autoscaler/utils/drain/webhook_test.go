@@ -0,0 +1,61 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestEvictionApproval(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req evictionApprovalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Pod.Namespace == "prod" {
+			w.Write([]byte(`{"approved": false, "reason": "change freeze"}`))
+			return
+		}
+		w.Write([]byte(`{"approved": true}`))
+	}))
+	defer server.Close()
+	webhook := &PreEvictionWebhook{URL: server.URL, TimeoutSeconds: 5}
+
+	approved, _, err := RequestEvictionApproval(context.Background(), webhook,
+		&apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "default"}})
+	assert.NoError(t, err)
+	assert.True(t, approved)
+
+	approved, reason, err := RequestEvictionApproval(context.Background(), webhook,
+		&apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "prod"}})
+	assert.NoError(t, err)
+	assert.False(t, approved)
+	assert.Equal(t, "change freeze", reason)
+
+	_, _, err = RequestEvictionApproval(context.Background(), &PreEvictionWebhook{URL: server.URL, CABundle: []byte("junk")},
+		&apiv1.Pod{ObjectMeta: apiv1.ObjectMeta{Name: "pod", Namespace: "default"}})
+	assert.Error(t, err)
+}